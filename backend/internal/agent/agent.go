@@ -0,0 +1,143 @@
+// Package agent runs the check pipeline on a remote probe (e.g. a VPS outside
+// Cyprus) and forwards results to the central instance, which stores them and
+// raises incidents per (target, probe).
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"cy-platforms-status-monitor/internal/monitor"
+)
+
+// IngestPath is where the central instance accepts batches.
+const IngestPath = "/ingest"
+
+// DefaultFlushInterval is how often Forward sends buffered results unless
+// Config.FlushInterval says otherwise.
+const DefaultFlushInterval = 5 * time.Second
+
+// maxRetryDelay caps the backoff between failed sends.
+const maxRetryDelay = 5 * time.Minute
+
+// Batch is the body of POST /ingest.
+type Batch struct {
+	Probe   string                `json:"probe"`
+	Results []monitor.CheckResult `json:"results"`
+}
+
+// Config configures Forward.
+type Config struct {
+	CentralURL string // base URL of the central instance, e.g. https://status.example.com
+	Token      string // sent as "Authorization: Bearer <token>"
+	Probe      string // name results are recorded under on the central side
+
+	Client        *http.Client  // defaults to a client with a 10s timeout
+	FlushInterval time.Duration // how often buffered results are sent (default 5s)
+	MaxBatch      int           // results per request; a full batch is sent right away (default 100)
+	MaxBuffered   int           // results kept while the central instance is unreachable (default 5000)
+}
+
+// Forward consumes resCh and pushes results to the central instance in
+// batches. After a failed send, results keep being buffered and retries only
+// happen on the flush ticker, with an exponentially growing delay (up to
+// maxRetryDelay), so an unreachable central instance never stalls the workers.
+// Once MaxBuffered results are waiting, the oldest are dropped. It returns
+// when ctx is done or resCh is closed.
+func Forward(ctx context.Context, resCh <-chan monitor.CheckResult, cfg Config) {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = 100
+	}
+	if cfg.MaxBuffered <= 0 {
+		cfg.MaxBuffered = 5000
+	}
+	cfg.MaxBuffered = max(cfg.MaxBuffered, cfg.MaxBatch)
+	endpoint := strings.TrimRight(cfg.CentralURL, "/") + IngestPath
+
+	var (
+		buf        []monitor.CheckResult
+		retryDelay time.Duration // 0 while sends succeed
+		retryAt    time.Time
+	)
+	flush := func() {
+		for len(buf) > 0 {
+			n := min(len(buf), cfg.MaxBatch)
+			if err := send(ctx, cfg, endpoint, buf[:n]); err != nil {
+				retryDelay = min(max(retryDelay*2, cfg.FlushInterval), maxRetryDelay)
+				retryAt = time.Now().Add(retryDelay)
+				log.Printf("agent: send %d results: %v (%d buffered, retrying in %s)", n, err, len(buf), retryDelay)
+				return
+			}
+			buf = buf[n:]
+			retryDelay = 0
+		}
+		buf = nil
+	}
+
+	t := time.NewTicker(cfg.FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case res, ok := <-resCh:
+			if !ok {
+				flush()
+				return
+			}
+			buf = append(buf, res)
+			if dropped := len(buf) - cfg.MaxBuffered; dropped > 0 {
+				log.Printf("agent: buffer full, dropping %d oldest results", dropped)
+				buf = buf[dropped:]
+			}
+			// While backing off, only the ticker retries.
+			if len(buf) >= cfg.MaxBatch && retryDelay == 0 {
+				flush()
+			}
+		case <-t.C:
+			if !time.Now().Before(retryAt) {
+				flush()
+			}
+		}
+	}
+}
+
+func send(ctx context.Context, cfg Config, endpoint string, results []monitor.CheckResult) error {
+	body, err := json.Marshal(Batch{Probe: cfg.Probe, Results: results})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+cfg.Token)
+
+	resp, err := cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("central returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
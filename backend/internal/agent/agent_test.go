@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"cy-platforms-status-monitor/internal/monitor"
+)
+
+// central is a fake /ingest endpoint that records the batches it accepts.
+type central struct {
+	mu      sync.Mutex
+	fail    bool // answer 503 instead of accepting
+	calls   int
+	batches []Batch
+}
+
+func (c *central) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if r.URL.Path != IngestPath || r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if c.fail {
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+		return
+	}
+	var b Batch
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.batches = append(c.batches, b)
+}
+
+func (c *central) setFail(fail bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fail = fail
+}
+
+// received returns the target names of every accepted result, in order, and
+// the size of each batch.
+func (c *central) received() (names []string, sizes []int, calls int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, b := range c.batches {
+		sizes = append(sizes, len(b.Results))
+		for _, r := range b.Results {
+			names = append(names, r.TargetName)
+		}
+	}
+	return names, sizes, c.calls
+}
+
+func results(n int) []monitor.CheckResult {
+	out := make([]monitor.CheckResult, n)
+	for i := range out {
+		out[i] = monitor.CheckResult{TargetName: fmt.Sprintf("r%d", i), At: time.Now(), Up: true}
+	}
+	return out
+}
+
+// forward runs Forward against c until resCh is closed.
+func forward(c *central, cfg Config) (chan<- monitor.CheckResult, <-chan struct{}) {
+	srv := httptest.NewServer(c)
+	cfg.CentralURL, cfg.Token, cfg.Probe = srv.URL+"/", "secret", "fra"
+
+	resCh := make(chan monitor.CheckResult)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer srv.Close()
+		Forward(context.Background(), resCh, cfg)
+	}()
+	return resCh, done
+}
+
+func TestForwardBatches(t *testing.T) {
+	c := &central{}
+	resCh, done := forward(c, Config{MaxBatch: 3, FlushInterval: 20 * time.Millisecond})
+
+	for _, r := range results(7) {
+		resCh <- r
+	}
+	// Two full batches go out at once, the last result on the next tick.
+	if _, sizes, _ := c.received(); fmt.Sprint(sizes) != "[3 3]" {
+		t.Errorf("batches before the tick = %v, want [3 3]", sizes)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(resCh)
+	<-done
+
+	names, sizes, _ := c.received()
+	if fmt.Sprint(sizes) != "[3 3 1]" || fmt.Sprint(names) != "[r0 r1 r2 r3 r4 r5 r6]" {
+		t.Errorf("received %v in batches %v, want r0..r6 in [3 3 1]", names, sizes)
+	}
+	if c.batches[0].Probe != "fra" {
+		t.Errorf("probe = %q, want fra", c.batches[0].Probe)
+	}
+}
+
+func TestForwardBacksOff(t *testing.T) {
+	c := &central{fail: true}
+	resCh, done := forward(c, Config{MaxBatch: 10, FlushInterval: 10 * time.Millisecond})
+
+	resCh <- results(1)[0]
+	// Retried every tick this would be ~20 calls; backing off 10, 20, 40, 80,
+	// 160ms it is at most 5.
+	time.Sleep(200 * time.Millisecond)
+	if _, _, calls := c.received(); calls < 2 || calls > 5 {
+		t.Errorf("%d sends to a failing central in 200ms, want 2-5 with backoff", calls)
+	}
+
+	c.setFail(false)
+	close(resCh)
+	<-done
+	if names, _, _ := c.received(); fmt.Sprint(names) != "[r0]" {
+		t.Errorf("received %v after recovery, want [r0] once", names)
+	}
+}
+
+func TestForwardDropsOldest(t *testing.T) {
+	c := &central{fail: true}
+	resCh, done := forward(c, Config{MaxBatch: 2, MaxBuffered: 4, FlushInterval: time.Hour})
+
+	// The first full batch fails; later results only fill the buffer.
+	for _, r := range results(8) {
+		resCh <- r
+	}
+	if _, _, calls := c.received(); calls != 1 {
+		t.Errorf("%d sends while backing off, want 1", calls)
+	}
+
+	c.setFail(false)
+	close(resCh)
+	<-done
+	names, sizes, _ := c.received()
+	if fmt.Sprint(names) != "[r4 r5 r6 r7]" || fmt.Sprint(sizes) != "[2 2]" {
+		t.Errorf("received %v in batches %v, want the newest 4 in [2 2]", names, sizes)
+	}
+}
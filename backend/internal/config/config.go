@@ -1,12 +1,22 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"cy-platforms-status-monitor/internal/monitor"
+
+	"github.com/andybalholm/cascadia"
 	"github.com/goccy/go-yaml"
 )
 
@@ -27,20 +37,196 @@ type MonitoringConfig struct {
 	UserAgent     string `yaml:"user_agent"`
 }
 type Target struct {
-	Name           string   `yaml:"name"`
-	URL            string   `yaml:"url"`
-	Method         string   `yaml:"method"`   // GET or HEAD
-	Interval       string   `yaml:"interval"` // e.g. "30s"
-	Timeout        string   `yaml:"timeout"`  // e.g. "5s"
-	ExpectedStatus int      `yaml:"expected_status,omitempty"`
-	Contains       string   `yaml:"contains,omitempty"`
-	MaxBodyBytes   int64    `yaml:"max_body_bytes,omitempty"`
-	Enabled        *bool    `yaml:"enabled,omitempty"`
-	Tags           []string `yaml:"tags,omitempty"`
+	Name            string         `yaml:"name"`
+	Type            string         `yaml:"type,omitempty"` // http (default), tcp, dns, tls, steps, grpc or websocket
+	URL             string         `yaml:"url"`
+	Method          string         `yaml:"method"`                     // GET (default), HEAD, POST, PUT, PATCH, DELETE, OPTIONS
+	Interval        string         `yaml:"interval"`                   // e.g. "30s"
+	Timeout         string         `yaml:"timeout"`                    // e.g. "5s"
+	DegradedLatency string         `yaml:"degraded_latency,omitempty"` // e.g. "3s"; slower passing checks are DEGRADED
+	Retries         int            `yaml:"retries,omitempty"`          // extra attempts before a check counts as failed
+	DownAfter       int            `yaml:"down_after,omitempty"`       // consecutive failures to confirm DOWN, default 1
+	UpAfter         int            `yaml:"up_after,omitempty"`         // consecutive successes to confirm UP, default 1
+	FlapThreshold   int            `yaml:"flap_threshold,omitempty"`   // transitions within flap_window that mean FLAPPING; 0 = off
+	FlapWindow      string         `yaml:"flap_window,omitempty"`      // e.g. "30m" (default)
+	DependsOn       []string       `yaml:"depends_on,omitempty"`       // parent targets; alerts are suppressed while a parent is DOWN
+	Quorum          int            `yaml:"quorum,omitempty"`           // probes that must see it DOWN (or DEGRADED) before an incident opens; 0/1 = each probe alone
+	RetryDelay      string         `yaml:"retry_delay,omitempty"`      // pause between attempts, default "1s"
+	ExpectedStatus  ExpectedStatus `yaml:"expected_status,omitempty"`  // 200, [200, 204, "300-399"], "2xx"
+	Contains        string         `yaml:"contains,omitempty"`
+	MaxBodyBytes    int64          `yaml:"max_body_bytes,omitempty"`
+	Enabled         *bool          `yaml:"enabled,omitempty"`
+	Tags            []string       `yaml:"tags,omitempty"`
+
+	// Request customization (http targets). String values support ${ENV} substitution.
+	Headers     map[string]string `yaml:"headers,omitempty"`
+	Body        string            `yaml:"body,omitempty"`
+	BodyFile    string            `yaml:"body_file,omitempty"` // relative to the config file
+	BasicAuth   *BasicAuth        `yaml:"basic_auth,omitempty"`
+	BearerToken string            `yaml:"bearer_token,omitempty"`
+
+	// Redirect policy (http targets)
+	FollowRedirects  *bool  `yaml:"follow_redirects,omitempty"`   // default true
+	MaxRedirects     int    `yaml:"max_redirects,omitempty"`      // default 10
+	ExpectedFinalURL string `yaml:"expected_final_url,omitempty"` // URL the chain must end at
+
+	JSONAssert    []JSONAssertion   `yaml:"json_assert,omitempty"`
+	ExpectHeaders []HeaderAssertion `yaml:"expect_headers,omitempty"`
+
+	Matches     []string `yaml:"matches,omitempty"`      // regexes the body must match
+	NotContains []string `yaml:"not_contains,omitempty"` // keywords the body must not contain
+	NotMatches  []string `yaml:"not_matches,omitempty"`  // regexes the body must not match
+
+	WatchContent *WatchContent `yaml:"watch_content,omitempty"` // GET only: alert on page content changes
+
+	TCP       TCPTarget       `yaml:"tcp,omitempty"`
+	DNS       DNSTarget       `yaml:"dns,omitempty"`
+	TLS       TLSTarget       `yaml:"tls,omitempty"` // applies to tls targets and https:// http targets
+	GRPC      GRPCTarget      `yaml:"grpc,omitempty"`
+	WebSocket WebSocketTarget `yaml:"websocket,omitempty"`
+
+	Steps []Step `yaml:"steps,omitempty"` // type "steps": requests run in order with a shared cookie jar
 
 	// Parsed durations (filled after load)
-	IntervalDur time.Duration `yaml:"-"`
-	TimeoutDur  time.Duration `yaml:"-"`
+	IntervalDur        time.Duration `yaml:"-"`
+	TimeoutDur         time.Duration `yaml:"-"`
+	DegradedLatencyDur time.Duration `yaml:"-"`
+	RetryDelayDur      time.Duration `yaml:"-"`
+	FlapWindowDur      time.Duration `yaml:"-"`
+
+	// Compiled patterns (filled after load)
+	MatchesRe    []*regexp.Regexp `yaml:"-"`
+	NotMatchesRe []*regexp.Regexp `yaml:"-"`
+}
+
+// hasBodyChecks reports whether any check needs the response body.
+func (t *Target) hasBodyChecks() bool {
+	return strings.TrimSpace(t.Contains) != "" ||
+		len(t.Matches) > 0 ||
+		len(t.NotContains) > 0 ||
+		len(t.NotMatches) > 0 ||
+		len(t.JSONAssert) > 0 ||
+		t.WatchContent != nil
+}
+
+// Fingerprint identifies t's configuration as loaded: defaults applied and
+// ${ENV} and body_file resolved. Compiled patterns and parsed durations are
+// derived from the source fields, so only those are hashed. It returns "" if
+// t cannot be encoded.
+func (t *Target) Fingerprint() string {
+	b, err := yaml.Marshal(t)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// WatchContent enables content change detection. With neither field set the
+// whole body is watched (visible text only for HTML).
+type WatchContent struct {
+	Selector string `yaml:"selector,omitempty"` // CSS selector, e.g. "main #content"
+	Region   string `yaml:"region,omitempty"`   // regex; first match (or capture group 1) is watched
+
+	SelectorSel cascadia.Selector `yaml:"-"` // compiled Selector (filled after load)
+	RegionRe    *regexp.Regexp    `yaml:"-"` // compiled Region (filled after load)
+}
+
+// TCPTarget holds options for type "tcp" targets (url: tcp://host:port).
+type TCPTarget struct {
+	BannerPrefix string `yaml:"banner_prefix,omitempty"` // e.g. "220" for SMTP, "SSH-" for SFTP
+}
+
+// DNSTarget holds options for type "dns" targets (url: dns://hostname).
+type DNSTarget struct {
+	RecordType string   `yaml:"record_type,omitempty"` // A (default), AAAA, CNAME, MX, TXT
+	Resolver   string   `yaml:"resolver,omitempty"`    // e.g. "1.1.1.1:53"; empty = system resolver
+	Expect     []string `yaml:"expect,omitempty"`      // values that must all be present
+	MinRecords int      `yaml:"min_records,omitempty"` // default 1
+}
+
+// TLSTarget holds certificate monitoring options (url: tls://host[:port] for type "tls").
+type TLSTarget struct {
+	ExpiryWarningDays int `yaml:"expiry_warning_days,omitempty"` // default 14
+}
+
+// GRPCTarget holds options for type "grpc" targets (url: grpc://host:port).
+type GRPCTarget struct {
+	Service            string `yaml:"service,omitempty"`              // health service name; empty = whole server
+	TLS                bool   `yaml:"tls,omitempty"`                  // dial with TLS (default plaintext)
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"` // with tls: accept any certificate
+}
+
+// WebSocketTarget holds options for type "websocket" targets (url: ws:// or wss://).
+// headers, basic_auth and bearer_token apply to the upgrade request.
+type WebSocketTarget struct {
+	Send   string `yaml:"send,omitempty"`   // text message sent after the handshake
+	Expect string `yaml:"expect,omitempty"` // regex a received message must match; with only send, any reply passes
+
+	ExpectRe *regexp.Regexp `yaml:"-"` // compiled Expect (filled after load)
+}
+
+// Step is one request of a "steps" target. It accepts the same request and
+// assertion fields as an http target (name, url, method, headers, body,
+// expected_status, contains, json_assert, ...) plus extract; target-level
+// fields such as interval, retries or depends_on are rejected. Values extracted
+// by earlier steps are referenced as {{var}} in url, headers, body and bearer_token.
+type Step struct {
+	Target  `yaml:",inline"`
+	Extract []Extraction `yaml:"extract,omitempty"`
+}
+
+// Extraction stores a value from a step's response in a variable. Exactly one
+// source must be set:
+//
+//	extract:
+//	  - { var: csrf, regex: 'name="csrf" value="([^"]+)"' } # first capture group
+//	  - { var: session, header: X-Session-Id }
+//	  - { var: user_id, json: "$.user.id" }
+type Extraction struct {
+	Var    string `yaml:"var"`
+	Header string `yaml:"header,omitempty"`
+	Regex  string `yaml:"regex,omitempty"`
+	JSON   string `yaml:"json,omitempty"`
+
+	// Compiled Regex (filled after load)
+	RegexRe *regexp.Regexp `yaml:"-"`
+}
+
+type BasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// JSONAssertion checks one value in a JSON response body, e.g.
+//
+//	json_assert:
+//	  - { path: "$.status", op: equals, value: "ok" }
+//	  - { path: "$.db", op: not_equals, value: "degraded" }
+//	  - { path: "$.queue.depth", op: lt, value: 100 }
+type JSONAssertion struct {
+	Path  string `yaml:"path"`
+	Op    string `yaml:"op"` // equals, not_equals, exists, regex, gt, gte, lt, lte
+	Value any    `yaml:"value,omitempty"`
+
+	// Value rendered as text, and compiled for op regex (filled after load)
+	ValueText string         `yaml:"-"`
+	ValueRe   *regexp.Regexp `yaml:"-"`
+}
+
+// HeaderAssertion checks one response header, e.g.
+//
+//	expect_headers:
+//	  - { name: Content-Type, op: regex, value: "^application/json" }
+//	  - { name: X-App-Version, op: present }
+//	  - { name: X-Maintenance, op: absent }
+type HeaderAssertion struct {
+	Name  string `yaml:"name"`
+	Op    string `yaml:"op,omitempty"` // exact (default with value), regex, present (default without), absent
+	Value string `yaml:"value,omitempty"`
+
+	// Compiled pattern for op regex (filled after load)
+	ValueRe *regexp.Regexp `yaml:"-"`
 }
 
 func Load(path string) (*Config, error) {
@@ -56,6 +242,10 @@ func Load(path string) (*Config, error) {
 
 	applyDefaults(&cfg)
 
+	if err := loadBodyFiles(&cfg, filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+
 	if err := validateAndNormalize(&cfg); err != nil {
 		return nil, err
 	}
@@ -63,6 +253,42 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// loadBodyFiles reads body_file into Body for every target that sets it.
+func loadBodyFiles(cfg *Config, baseDir string) error {
+	for i := range cfg.Targets {
+		t := &cfg.Targets[i]
+
+		if err := loadBodyFile(t, t.Name, baseDir); err != nil {
+			return err
+		}
+		for j := range t.Steps {
+			if err := loadBodyFile(&t.Steps[j].Target, fmt.Sprintf("%s/steps[%d]", t.Name, j), baseDir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func loadBodyFile(t *Target, label, baseDir string) error {
+	bodyFile := strings.TrimSpace(t.BodyFile)
+	if bodyFile == "" {
+		return nil
+	}
+	if t.Body != "" {
+		return fmt.Errorf("config: target %q sets both body and body_file", label)
+	}
+	if !filepath.IsAbs(bodyFile) {
+		bodyFile = filepath.Join(baseDir, bodyFile)
+	}
+	b, err := os.ReadFile(bodyFile)
+	if err != nil {
+		return fmt.Errorf("config: target %q read body_file: %w", label, err)
+	}
+	t.Body = string(b)
+	return nil
+}
+
 func applyDefaults(cfg *Config) {
 	// Server defaults
 	if strings.TrimSpace(cfg.Server.Addr) == "" {
@@ -93,8 +319,8 @@ func applyDefaults(cfg *Config) {
 			t.Enabled = &v
 		}
 
-		if strings.TrimSpace(t.Method) == "" {
-			t.Method = "GET"
+		if strings.TrimSpace(t.Type) == "" {
+			t.Type = monitor.TypeHTTP
 		}
 		if strings.TrimSpace(t.Interval) == "" {
 			t.Interval = "30s"
@@ -102,15 +328,38 @@ func applyDefaults(cfg *Config) {
 		if strings.TrimSpace(t.Timeout) == "" {
 			t.Timeout = "5s"
 		}
-		if t.ExpectedStatus == 0 {
-			t.ExpectedStatus = 200
+		applyRequestDefaults(t)
+
+		for j := range t.Steps {
+			applyRequestDefaults(&t.Steps[j].Target)
 		}
-		if t.MaxBodyBytes == 0 {
-			t.MaxBodyBytes = 64 * 1024 // 64KB
+		// steps targets display the first step's URL unless one is given
+		if strings.TrimSpace(t.URL) == "" && len(t.Steps) > 0 {
+			t.URL = t.Steps[0].URL
 		}
 	}
 }
 
+// applyRequestDefaults fills request-level defaults shared by targets and steps.
+func applyRequestDefaults(t *Target) {
+	if t.FollowRedirects == nil {
+		v := true
+		t.FollowRedirects = &v
+	}
+	if t.MaxRedirects == 0 {
+		t.MaxRedirects = 10
+	}
+	if strings.TrimSpace(t.Method) == "" {
+		t.Method = "GET"
+	}
+	if t.MaxBodyBytes == 0 {
+		t.MaxBodyBytes = 64 * 1024 // 64KB
+	}
+	if t.TLS.ExpiryWarningDays == 0 {
+		t.TLS.ExpiryWarningDays = 14
+	}
+}
+
 func validateAndNormalize(cfg *Config) error {
 	if len(cfg.Targets) == 0 {
 		return errors.New("config: no targets provided")
@@ -122,6 +371,7 @@ func validateAndNormalize(cfg *Config) error {
 		t := &cfg.Targets[i]
 
 		t.Name = strings.TrimSpace(t.Name)
+		t.Type = strings.ToLower(strings.TrimSpace(t.Type))
 		t.URL = strings.TrimSpace(t.URL)
 		t.Method = strings.ToUpper(strings.TrimSpace(t.Method))
 
@@ -136,14 +386,38 @@ func validateAndNormalize(cfg *Config) error {
 		if t.URL == "" {
 			return fmt.Errorf("config: target %q missing url", t.Name)
 		}
-		if !strings.HasPrefix(t.URL, "http://") && !strings.HasPrefix(t.URL, "https://") {
-			return fmt.Errorf("config: target %q url must start with http:// or https://", t.Name)
-		}
 
-		switch t.Method {
-		case "GET", "HEAD":
+		switch t.Type {
+		case monitor.TypeHTTP:
+			if err := validateHTTPTarget(t); err != nil {
+				return err
+			}
+		case monitor.TypeTCP:
+			if err := validateTCPTarget(t); err != nil {
+				return err
+			}
+		case monitor.TypeDNS:
+			if err := validateDNSTarget(t); err != nil {
+				return err
+			}
+		case monitor.TypeTLS:
+			if err := validateTLSTarget(t); err != nil {
+				return err
+			}
+		case monitor.TypeSteps:
+			if err := validateStepsTarget(t); err != nil {
+				return err
+			}
+		case monitor.TypeGRPC:
+			if err := validateGRPCTarget(t); err != nil {
+				return err
+			}
+		case monitor.TypeWebSocket:
+			if err := validateWebSocketTarget(t); err != nil {
+				return err
+			}
 		default:
-			return fmt.Errorf("config: target %q invalid method %q (use GET or HEAD)", t.Name, t.Method)
+			return fmt.Errorf("config: target %q unknown type %q", t.Name, t.Type)
 		}
 
 		intervalDur, err := time.ParseDuration(t.Interval)
@@ -164,18 +438,648 @@ func validateAndNormalize(cfg *Config) error {
 		}
 		t.TimeoutDur = timeoutDur
 
-		if t.ExpectedStatus < 100 || t.ExpectedStatus > 599 {
-			return fmt.Errorf("config: target %q expected_status must be 100..599", t.Name)
+		if raw := strings.TrimSpace(t.DegradedLatency); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return fmt.Errorf("config: target %q invalid degraded_latency %q: %w", t.Name, raw, err)
+			}
+			if d <= 0 || d >= timeoutDur {
+				return fmt.Errorf("config: target %q degraded_latency must be > 0 and below timeout", t.Name)
+			}
+			t.DegradedLatencyDur = d
+		}
+
+		if t.DownAfter < 0 || t.UpAfter < 0 {
+			return fmt.Errorf("config: target %q down_after and up_after cannot be negative", t.Name)
+		}
+		t.DownAfter, t.UpAfter = max(t.DownAfter, 1), max(t.UpAfter, 1)
+
+		if t.Quorum < 0 {
+			return fmt.Errorf("config: target %q quorum cannot be negative", t.Name)
+		}
+
+		if t.FlapThreshold < 0 || t.FlapThreshold == 1 {
+			return fmt.Errorf("config: target %q flap_threshold must be 0 (off) or at least 2", t.Name)
+		}
+		// Flapping is tracked per probe, but with a quorum per-probe flips raise nothing.
+		if t.FlapThreshold > 0 && t.Quorum > 1 {
+			return fmt.Errorf("config: target %q flap_threshold cannot be combined with quorum", t.Name)
+		}
+		t.FlapWindowDur = 30 * time.Minute
+		if raw := strings.TrimSpace(t.FlapWindow); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("config: target %q invalid flap_window %q", t.Name, raw)
+			}
+			if d <= intervalDur {
+				return fmt.Errorf("config: target %q flap_window must be longer than interval", t.Name)
+			}
+			t.FlapWindowDur = d
+		}
+
+		if t.Retries < 0 {
+			return fmt.Errorf("config: target %q retries cannot be negative", t.Name)
+		}
+		t.RetryDelayDur = time.Second
+		if raw := strings.TrimSpace(t.RetryDelay); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d < 0 {
+				return fmt.Errorf("config: target %q invalid retry_delay %q", t.Name, raw)
+			}
+			t.RetryDelayDur = d
+		}
+		// All attempts must fit in one slot so retries never overlap the next scheduled check.
+		if t.Retries > 0 {
+			worst := time.Duration(t.Retries+1)*timeoutDur + time.Duration(t.Retries)*t.RetryDelayDur
+			if worst >= intervalDur {
+				return fmt.Errorf("config: target %q retries: %d attempts with timeout %s and retry_delay %s exceed interval %s",
+					t.Name, t.Retries+1, timeoutDur, t.RetryDelayDur, intervalDur)
+			}
 		}
 
 		if t.MaxBodyBytes < 0 {
 			return fmt.Errorf("config: target %q max_body_bytes cannot be negative", t.Name)
 		}
 
-		// If using HEAD, contains check won’t work (no body). Allow it but warn by failing fast for clarity.
-		if t.Method == "HEAD" && strings.TrimSpace(t.Contains) != "" {
-			return fmt.Errorf("config: target %q uses method HEAD but has contains check; use GET instead", t.Name)
+		if t.TLS.ExpiryWarningDays < 0 {
+			return fmt.Errorf("config: target %q tls.expiry_warning_days cannot be negative", t.Name)
+		}
+	}
+
+	return validateDependencies(cfg.Targets)
+}
+
+// validateDependencies checks that depends_on names existing targets and that
+// the dependency graph has no cycles.
+func validateDependencies(targets []Target) error {
+	deps := make(map[string][]string, len(targets))
+	disabled := make(map[string]bool)
+	for i := range targets {
+		t := &targets[i]
+		deps[t.Name] = nil
+		disabled[t.Name] = t.Enabled != nil && !*t.Enabled
+	}
+	for i := range targets {
+		t := &targets[i]
+		for j, d := range t.DependsOn {
+			d = strings.TrimSpace(d)
+			t.DependsOn[j] = d
+			if d == t.Name {
+				return fmt.Errorf("config: target %q cannot depend on itself", t.Name)
+			}
+			if _, ok := deps[d]; !ok {
+				return fmt.Errorf("config: target %q depends_on unknown target %q", t.Name, d)
+			}
+			// A disabled parent never reports, so it would never mute anything.
+			if disabled[d] && !disabled[t.Name] {
+				return fmt.Errorf("config: target %q depends_on disabled target %q", t.Name, d)
+			}
+		}
+		deps[t.Name] = t.DependsOn
+	}
+
+	// Depth-first search; reaching a target still on the stack means a cycle.
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	mark := make(map[string]int, len(deps))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch mark[name] {
+		case visiting:
+			return fmt.Errorf("config: dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case done:
+			return nil
+		}
+		mark[name] = visiting
+		for _, d := range deps[name] {
+			if err := visit(d, append(path, name)); err != nil {
+				return err
+			}
+		}
+		mark[name] = done
+		return nil
+	}
+	for i := range targets {
+		if err := visit(targets[i].Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateHTTPTarget checks the fields that only apply to "http" targets.
+func validateHTTPTarget(t *Target) error {
+	if !strings.HasPrefix(t.URL, "http://") && !strings.HasPrefix(t.URL, "https://") {
+		return fmt.Errorf("config: target %q url must start with http:// or https://", t.Name)
+	}
+	return validateHTTPRequest(t)
+}
+
+// validateHTTPRequest is validateHTTPTarget without the url scheme check, for
+// step urls whose scheme is only known once {{var}}s are filled in.
+func validateHTTPRequest(t *Target) error {
+	switch t.Method {
+	case "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS":
+	default:
+		return fmt.Errorf("config: target %q invalid method %q (use GET, HEAD, POST, PUT, PATCH, DELETE or OPTIONS)", t.Name, t.Method)
+	}
+
+	if err := expandRequestEnv(t); err != nil {
+		return err
+	}
+	if t.BasicAuth != nil && t.BearerToken != "" {
+		return fmt.Errorf("config: target %q sets both basic_auth and bearer_token", t.Name)
+	}
+	if t.BasicAuth != nil && t.BasicAuth.Username == "" {
+		return fmt.Errorf("config: target %q basic_auth missing username", t.Name)
+	}
+	if t.Body != "" && (t.Method == "GET" || t.Method == "HEAD") {
+		return fmt.Errorf("config: target %q sends a body with method %s; use POST, PUT or PATCH", t.Name, t.Method)
+	}
+
+	if t.MaxRedirects < 0 {
+		return fmt.Errorf("config: target %q max_redirects cannot be negative", t.Name)
+	}
+	t.ExpectedFinalURL = strings.TrimSpace(t.ExpectedFinalURL)
+	if t.ExpectedFinalURL != "" {
+		if !*t.FollowRedirects {
+			return fmt.Errorf("config: target %q sets expected_final_url but follow_redirects is false", t.Name)
+		}
+		if !strings.HasPrefix(t.ExpectedFinalURL, "http://") && !strings.HasPrefix(t.ExpectedFinalURL, "https://") {
+			return fmt.Errorf("config: target %q expected_final_url must start with http:// or https://", t.Name)
+		}
+	}
+
+	for _, r := range t.ExpectedStatus {
+		if r.Min < 100 || r.Max > 599 || r.Min > r.Max {
+			return fmt.Errorf("config: target %q expected_status must be codes or ranges within 100..599", t.Name)
+		}
+	}
+
+	// If using HEAD, body checks won’t work (no body). Allow it but warn by failing fast for clarity.
+	if t.Method == "HEAD" && t.hasBodyChecks() {
+		return fmt.Errorf("config: target %q uses method HEAD but has body checks; use GET instead", t.Name)
+	}
+
+	var err error
+	if t.MatchesRe, err = compilePatterns(t.Name, "matches", t.Matches); err != nil {
+		return err
+	}
+	if t.NotMatchesRe, err = compilePatterns(t.Name, "not_matches", t.NotMatches); err != nil {
+		return err
+	}
+	for i, kw := range t.NotContains {
+		if strings.TrimSpace(kw) == "" {
+			return fmt.Errorf("config: target %q not_contains[%d] is empty", t.Name, i)
+		}
+	}
+
+	for i := range t.ExpectHeaders {
+		if err := validateHeaderAssertion(t.Name, i, &t.ExpectHeaders[i]); err != nil {
+			return err
+		}
+	}
+
+	for i := range t.JSONAssert {
+		if err := validateJSONAssertion(t.Name, i, &t.JSONAssert[i]); err != nil {
+			return err
+		}
+	}
+
+	if t.WatchContent != nil {
+		if err := validateWatchContent(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateWatchContent compiles the watch_content selector or region.
+func validateWatchContent(t *Target) error {
+	w := t.WatchContent
+	if t.Method != "GET" {
+		return fmt.Errorf("config: target %q watch_content requires method GET", t.Name)
+	}
+	w.Selector = strings.TrimSpace(w.Selector)
+	if w.Selector != "" && w.Region != "" {
+		return fmt.Errorf("config: target %q watch_content: set selector or region, not both", t.Name)
+	}
+
+	if w.Selector != "" {
+		sel, err := cascadia.Compile(w.Selector)
+		if err != nil {
+			return fmt.Errorf("config: target %q invalid watch_content.selector %q: %w", t.Name, w.Selector, err)
+		}
+		w.SelectorSel = sel
+	}
+	if w.Region != "" {
+		re, err := regexp.Compile(w.Region)
+		if err != nil {
+			return fmt.Errorf("config: target %q invalid watch_content.region %q: %w", t.Name, w.Region, err)
+		}
+		w.RegionRe = re
+	}
+
+	return nil
+}
+
+// expandRequestEnv substitutes ${ENV} references in the request fields
+// that typically carry secrets (headers, body, credentials).
+func expandRequestEnv(t *Target) error {
+	expand := func(field string, v *string) error {
+		out, err := expandEnv(*v)
+		if err != nil {
+			return fmt.Errorf("config: target %q %s: %w", t.Name, field, err)
+		}
+		*v = out
+		return nil
+	}
+
+	for k, v := range t.Headers {
+		if err := expand("headers."+k, &v); err != nil {
+			return err
+		}
+		t.Headers[k] = v
+	}
+	if err := expand("body", &t.Body); err != nil {
+		return err
+	}
+	if err := expand("bearer_token", &t.BearerToken); err != nil {
+		return err
+	}
+	if t.BasicAuth != nil {
+		if err := expand("basic_auth.username", &t.BasicAuth.Username); err != nil {
+			return err
+		}
+		if err := expand("basic_auth.password", &t.BasicAuth.Password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compilePatterns compiles a list of body regexes, reporting the offending entry.
+func compilePatterns(target, field string, patterns []string) ([]*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for i, p := range patterns {
+		if strings.TrimSpace(p) == "" {
+			return nil, fmt.Errorf("config: target %q %s[%d] is empty", target, field, i)
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("config: target %q %s[%d] invalid regex %q: %w", target, field, i, p, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// validateHeaderAssertion fills the default operator and compiles regex values.
+func validateHeaderAssertion(target string, i int, a *HeaderAssertion) error {
+	a.Name = strings.TrimSpace(a.Name)
+	a.Op = strings.ToLower(strings.TrimSpace(a.Op))
+	a.Value = strings.TrimSpace(a.Value)
+
+	if a.Name == "" {
+		return fmt.Errorf("config: target %q expect_headers[%d] missing name", target, i)
+	}
+	if a.Op == "" {
+		a.Op = "present"
+		if a.Value != "" {
+			a.Op = "exact"
+		}
+	}
+
+	switch a.Op {
+	case "present", "absent":
+	case "exact":
+		if a.Value == "" {
+			return fmt.Errorf("config: target %q expect_headers[%d] op exact requires a value", target, i)
+		}
+	case "regex":
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return fmt.Errorf("config: target %q expect_headers[%d] invalid regex %q: %w", target, i, a.Value, err)
+		}
+		a.ValueRe = re
+	default:
+		return fmt.Errorf("config: target %q expect_headers[%d] invalid op %q (use exact, regex, present or absent)", target, i, a.Op)
+	}
+
+	return nil
+}
+
+// validateJSONAssertion normalizes the operator and checks that the value fits it.
+func validateJSONAssertion(target string, i int, a *JSONAssertion) error {
+	a.Path = strings.TrimSpace(a.Path)
+	a.Op = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(a.Op)), "-", "_")
+
+	if a.Path == "" {
+		return fmt.Errorf("config: target %q json_assert[%d] missing path", target, i)
+	}
+	if strings.Count(a.Path, "[") != strings.Count(a.Path, "]") {
+		return fmt.Errorf("config: target %q json_assert[%d] invalid path %q", target, i, a.Path)
+	}
+
+	if a.Value != nil {
+		a.ValueText = fmt.Sprint(a.Value)
+	}
+
+	switch a.Op {
+	case "exists":
+	case "equals", "not_equals":
+		if a.Value == nil {
+			return fmt.Errorf("config: target %q json_assert[%d] op %s requires a value", target, i, a.Op)
+		}
+	case "regex":
+		if a.Value == nil {
+			return fmt.Errorf("config: target %q json_assert[%d] op regex requires a value", target, i)
+		}
+		re, err := regexp.Compile(a.ValueText)
+		if err != nil {
+			return fmt.Errorf("config: target %q json_assert[%d] invalid regex %q: %w", target, i, a.ValueText, err)
+		}
+		a.ValueRe = re
+	case "gt", "gte", "lt", "lte":
+		if _, err := strconv.ParseFloat(a.ValueText, 64); err != nil {
+			return fmt.Errorf("config: target %q json_assert[%d] op %s requires a numeric value", target, i, a.Op)
+		}
+	default:
+		return fmt.Errorf("config: target %q json_assert[%d] invalid op %q (use equals, not_equals, exists, regex, gt, gte, lt or lte)", target, i, a.Op)
+	}
+
+	return nil
+}
+
+// validateTCPTarget checks that a "tcp" target points at tcp://host:port.
+func validateTCPTarget(t *Target) error {
+	u, err := url.Parse(t.URL)
+	if err != nil || u.Scheme != "tcp" {
+		return fmt.Errorf("config: target %q url must look like tcp://host:port", t.Name)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil || host == "" || port == "" {
+		return fmt.Errorf("config: target %q url must look like tcp://host:port", t.Name)
+	}
+	if t.hasBodyChecks() {
+		return fmt.Errorf("config: target %q: body checks are not supported for tcp targets; use tcp.banner_prefix", t.Name)
+	}
+
+	return nil
+}
+
+// validateDNSTarget checks the dns:// url and the record assertions.
+func validateDNSTarget(t *Target) error {
+	u, err := url.Parse(t.URL)
+	if err != nil || u.Scheme != "dns" || u.Host == "" {
+		return fmt.Errorf("config: target %q url must look like dns://hostname", t.Name)
+	}
+	if u.Port() != "" {
+		return fmt.Errorf("config: target %q dns url cannot have a port; set dns.resolver to query another server", t.Name)
+	}
+
+	d := &t.DNS
+	d.RecordType = strings.ToUpper(strings.TrimSpace(d.RecordType))
+	if d.RecordType == "" {
+		d.RecordType = "A"
+	}
+	switch d.RecordType {
+	case "A", "AAAA", "CNAME", "MX", "TXT":
+	default:
+		return fmt.Errorf("config: target %q invalid dns.record_type %q (use A, AAAA, CNAME, MX or TXT)", t.Name, d.RecordType)
+	}
+
+	d.Resolver = strings.TrimSpace(d.Resolver)
+	if d.MinRecords < 0 {
+		return fmt.Errorf("config: target %q dns.min_records cannot be negative", t.Name)
+	}
+	if d.RecordType == "A" || d.RecordType == "AAAA" {
+		for _, v := range d.Expect {
+			if net.ParseIP(strings.TrimSpace(v)) == nil {
+				return fmt.Errorf("config: target %q dns.expect value %q is not an IP address", t.Name, v)
+			}
+		}
+	}
+	if t.hasBodyChecks() {
+		return fmt.Errorf("config: target %q: body checks are not supported for dns targets; use dns.expect", t.Name)
+	}
+
+	return nil
+}
+
+// validateTLSTarget checks that a "tls" target points at tls://host[:port].
+func validateTLSTarget(t *Target) error {
+	u, err := url.Parse(t.URL)
+	if err != nil || u.Scheme != "tls" || u.Hostname() == "" {
+		return fmt.Errorf("config: target %q url must look like tls://host[:port]", t.Name)
+	}
+	if t.hasBodyChecks() {
+		return fmt.Errorf("config: target %q: body checks are not supported for tls targets", t.Name)
+	}
+
+	return nil
+}
+
+// validateGRPCTarget checks that a "grpc" target points at grpc://host:port.
+func validateGRPCTarget(t *Target) error {
+	u, err := url.Parse(t.URL)
+	if err != nil || u.Scheme != "grpc" {
+		return fmt.Errorf("config: target %q url must look like grpc://host:port", t.Name)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil || host == "" || port == "" {
+		return fmt.Errorf("config: target %q url must look like grpc://host:port", t.Name)
+	}
+	if t.hasBodyChecks() {
+		return fmt.Errorf("config: target %q: body checks are not supported for grpc targets", t.Name)
+	}
+	if t.GRPC.InsecureSkipVerify && !t.GRPC.TLS {
+		return fmt.Errorf("config: target %q grpc.insecure_skip_verify requires grpc.tls", t.Name)
+	}
+	t.GRPC.Service = strings.TrimSpace(t.GRPC.Service)
+
+	return nil
+}
+
+// validateWebSocketTarget checks the ws:// url and compiles websocket.expect.
+func validateWebSocketTarget(t *Target) error {
+	u, err := url.Parse(t.URL)
+	if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+		return fmt.Errorf("config: target %q url must start with ws:// or wss://", t.Name)
+	}
+	if t.hasBodyChecks() {
+		return fmt.Errorf("config: target %q: body checks are not supported for websocket targets; use websocket.expect", t.Name)
+	}
+	if err := expandRequestEnv(t); err != nil {
+		return err
+	}
+	if t.BasicAuth != nil && t.BearerToken != "" {
+		return fmt.Errorf("config: target %q sets both basic_auth and bearer_token", t.Name)
+	}
+
+	ws := &t.WebSocket
+	out, err := expandEnv(ws.Send)
+	if err != nil {
+		return fmt.Errorf("config: target %q websocket.send: %w", t.Name, err)
+	}
+	ws.Send = out
+	if ws.Expect != "" {
+		re, err := regexp.Compile(ws.Expect)
+		if err != nil {
+			return fmt.Errorf("config: target %q invalid websocket.expect %q: %w", t.Name, ws.Expect, err)
+		}
+		ws.ExpectRe = re
+	}
+
+	return nil
+}
+
+// validateStepsTarget validates each step as an http request and checks that
+// every {{var}} is extracted by an earlier step.
+func validateStepsTarget(t *Target) error {
+	if len(t.Steps) == 0 {
+		return fmt.Errorf("config: target %q of type steps needs at least one step", t.Name)
+	}
+	if t.hasBodyChecks() || len(t.ExpectHeaders) > 0 {
+		return fmt.Errorf("config: target %q: put assertions on individual steps", t.Name)
+	}
+
+	defined := make(map[string]struct{})
+	for i := range t.Steps {
+		s := &t.Steps[i]
+
+		s.Name = strings.TrimSpace(s.Name)
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("step %d", i+1)
+		}
+		s.URL = strings.TrimSpace(s.URL)
+		s.Method = strings.ToUpper(strings.TrimSpace(s.Method))
+		if s.URL == "" {
+			return fmt.Errorf("config: target %q step %q missing url", t.Name, s.Name)
+		}
+		if f := targetOnlyFields(&s.Target); len(f) > 0 {
+			return fmt.Errorf("config: target %q step %q sets %s; set these on the target, not on a step", t.Name, s.Name, strings.Join(f, ", "))
+		}
+
+		for _, v := range []string{s.URL, s.Body, s.BearerToken} {
+			if err := checkStepVars(t.Name, s.Name, v, defined); err != nil {
+				return err
+			}
+		}
+		for _, v := range s.Headers {
+			if err := checkStepVars(t.Name, s.Name, v, defined); err != nil {
+				return err
+			}
+		}
+
+		// Reuse the http validation; qualify the name so errors point at the step.
+		// A url starting with {{var}} gets its scheme checked at run time.
+		name := s.Name
+		s.Name = t.Name + "/" + name
+		var err error
+		if strings.HasPrefix(s.URL, "{{") {
+			err = validateHTTPRequest(&s.Target)
+		} else {
+			err = validateHTTPTarget(&s.Target)
+		}
+		s.Name = name
+		if err != nil {
+			return err
+		}
+
+		for j := range s.Extract {
+			e := &s.Extract[j]
+			if err := validateExtraction(t.Name, s.Name, j, e); err != nil {
+				return err
+			}
+			if s.Method == "HEAD" && e.Header == "" {
+				return fmt.Errorf("config: target %q step %q extracts from the body but uses HEAD", t.Name, s.Name)
+			}
+			defined[e.Var] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// targetOnlyFields lists the fields of t, by YAML name, that configure a
+// target as a whole rather than a single request, and so mean nothing on a step.
+func targetOnlyFields(t *Target) []string {
+	fields := []struct {
+		name string
+		set  bool
+	}{
+		{"type", t.Type != ""},
+		{"interval", t.Interval != ""},
+		{"timeout", t.Timeout != ""},
+		{"degraded_latency", t.DegradedLatency != ""},
+		{"retries", t.Retries != 0},
+		{"retry_delay", t.RetryDelay != ""},
+		{"down_after", t.DownAfter != 0},
+		{"up_after", t.UpAfter != 0},
+		{"flap_threshold", t.FlapThreshold != 0},
+		{"flap_window", t.FlapWindow != ""},
+		{"depends_on", len(t.DependsOn) > 0},
+		{"quorum", t.Quorum != 0},
+		{"enabled", t.Enabled != nil},
+		{"tags", len(t.Tags) > 0},
+		{"watch_content", t.WatchContent != nil},
+		{"tcp", t.TCP != TCPTarget{}},
+		{"dns", t.DNS.RecordType != "" || t.DNS.Resolver != "" || len(t.DNS.Expect) > 0 || t.DNS.MinRecords != 0},
+		{"grpc", t.GRPC != GRPCTarget{}},
+		{"websocket", t.WebSocket.Send != "" || t.WebSocket.Expect != ""},
+		{"steps", len(t.Steps) > 0},
+	}
+
+	var set []string
+	for _, f := range fields {
+		if f.set {
+			set = append(set, f.name)
+		}
+	}
+	return set
+}
+
+// checkStepVars fails if s references a variable no earlier step extracts.
+func checkStepVars(target, step, s string, defined map[string]struct{}) error {
+	for _, m := range monitor.StepVarRef.FindAllStringSubmatch(s, -1) {
+		if _, ok := defined[m[1]]; !ok {
+			return fmt.Errorf("config: target %q step %q uses {{%s}} before it is extracted", target, step, m[1])
+		}
+	}
+	return nil
+}
+
+// validateExtraction checks that exactly one source is set and compiles regexes.
+func validateExtraction(target, step string, i int, e *Extraction) error {
+	e.Var = strings.TrimSpace(e.Var)
+	if e.Var == "" {
+		return fmt.Errorf("config: target %q step %q extract[%d] missing var", target, step, i)
+	}
+
+	sources := 0
+	for _, v := range []string{e.Header, e.Regex, e.JSON} {
+		if strings.TrimSpace(v) != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("config: target %q step %q extract %q must set exactly one of header, regex or json", target, step, e.Var)
+	}
+
+	if e.Regex != "" {
+		re, err := regexp.Compile(e.Regex)
+		if err != nil {
+			return fmt.Errorf("config: target %q step %q extract %q invalid regex: %w", target, step, e.Var, err)
 		}
+		e.RegexRe = re
 	}
 
 	return nil
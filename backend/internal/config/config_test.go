@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		deps     map[string][]string // target -> depends_on
+		disabled []string
+		wantErr  string // substring; "" for no error
+	}{
+		{name: "none", deps: map[string][]string{"a": nil, "b": nil}},
+		{name: "chain", deps: map[string][]string{"a": nil, "b": {"a"}, "c": {"b"}}},
+		{name: "diamond", deps: map[string][]string{"a": nil, "b": {"a"}, "c": {"a"}, "d": {"b", "c"}}},
+		{name: "names are trimmed", deps: map[string][]string{"a": nil, "b": {" a "}}},
+		{name: "self", deps: map[string][]string{"a": {"a"}}, wantErr: "cannot depend on itself"},
+		{name: "unknown", deps: map[string][]string{"a": {"x"}}, wantErr: `unknown target "x"`},
+		{name: "two-cycle", deps: map[string][]string{"a": {"b"}, "b": {"a"}}, wantErr: "dependency cycle"},
+		{name: "long cycle", deps: map[string][]string{"a": {"c"}, "b": {"a"}, "c": {"b"}, "d": {"a"}}, wantErr: "dependency cycle"},
+		{name: "disabled parent", deps: map[string][]string{"a": nil, "b": {"a"}}, disabled: []string{"a"}, wantErr: `disabled target "a"`},
+		{name: "both disabled", deps: map[string][]string{"a": nil, "b": {"a"}}, disabled: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		var targets []Target
+		for name, deps := range tt.deps {
+			target := Target{Name: name, DependsOn: append([]string(nil), deps...)}
+			if slices.Contains(tt.disabled, name) {
+				target.Enabled = new(bool)
+			}
+			targets = append(targets, target)
+		}
+
+		err := validateDependencies(targets)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.wantErr != "" && err == nil:
+			t.Errorf("%s: want error containing %q", tt.name, tt.wantErr)
+		case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+			t.Errorf("%s: error %q does not contain %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateDNSTarget(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr string // substring; "" for no error
+	}{
+		{url: "dns://example.com"},
+		{url: "dns://example.com:53", wantErr: "cannot have a port"},
+		{url: "https://example.com", wantErr: "must look like dns://hostname"},
+		{url: "dns://", wantErr: "must look like dns://hostname"},
+	}
+
+	for _, tt := range tests {
+		err := validateDNSTarget(&Target{Name: "dns", URL: tt.url})
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.url, err)
+		case tt.wantErr != "" && err == nil:
+			t.Errorf("%s: want error containing %q", tt.url, tt.wantErr)
+		case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+			t.Errorf("%s: error %q does not contain %q", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	load := func(yml string) Target {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(yml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg.Targets[0]
+	}
+	const base = `
+targets:
+  - name: web
+    url: https://example.com
+    matches: ["ok|fine"]
+    watch_content:
+      selector: main
+`
+	a, b := load(base), load(base)
+	if a.Fingerprint() == "" || a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("same config: fingerprints %q and %q, want equal", a.Fingerprint(), b.Fingerprint())
+	}
+
+	for _, changed := range []string{
+		strings.Replace(base, "ok|fine", "ok", 1),
+		strings.Replace(base, "selector: main", "selector: article", 1),
+		base + "    interval: 10s\n",
+		base + "    headers: {X-Probe: a}\n",
+		base + "    down_after: 3\n",
+	} {
+		if c := load(changed); c.Fingerprint() == a.Fingerprint() {
+			t.Errorf("changed config has the same fingerprint:%s", changed)
+		}
+	}
+}
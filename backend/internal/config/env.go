@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// envRef matches ${NAME} references. Bare $NAME is left alone so request
+// bodies can contain dollar signs.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${NAME} with the value of the environment variable NAME.
+// Unset variables are an error so a missing secret fails at load time instead
+// of sending an empty credential.
+func expandEnv(s string) (string, error) {
+	var missing string
+	out := envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return v
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+	return out, nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("PINGCY_TEST_TOKEN", "s3cret")
+	t.Setenv("PINGCY_TEST_EMPTY", "")
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "Bearer ${PINGCY_TEST_TOKEN}", want: "Bearer s3cret"},
+		{in: "${PINGCY_TEST_TOKEN}:${PINGCY_TEST_TOKEN}", want: "s3cret:s3cret"},
+		{in: "empty=${PINGCY_TEST_EMPTY}", want: "empty="},
+		{in: `{"price": "$5", "ref": "$PINGCY_TEST_TOKEN"}`, want: `{"price": "$5", "ref": "$PINGCY_TEST_TOKEN"}`},
+		{in: "no refs", want: "no refs"},
+		{in: "${PINGCY_TEST_UNSET_VARIABLE}", wantErr: true},
+		{in: "${PINGCY_TEST_TOKEN} ${PINGCY_TEST_UNSET_VARIABLE}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := expandEnv(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expandEnv(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandEnv(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
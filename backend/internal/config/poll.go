@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Poll checks the file at path every interval and calls reload when its
+// modification time or size changed, or when the process receives SIGHUP.
+// It polls rather than using filesystem notifications, so edits show up
+// within one interval. reload is responsible for calling Load and for keeping
+// the running config when the new one is invalid. Poll returns immediately;
+// it stops with ctx.
+func Poll(ctx context.Context, path string, interval time.Duration, reload func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	last, err := os.Stat(path)
+	if err != nil {
+		log.Printf("config: poll %s: %v", path, err)
+	}
+
+	go func() {
+		defer signal.Stop(hup)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				log.Printf("config: SIGHUP, reloading %s", path)
+				last, _ = os.Stat(path)
+				reload()
+			case <-t.C:
+				fi, err := os.Stat(path)
+				if err != nil {
+					// Editors may replace the file in two steps; try again next tick.
+					continue
+				}
+				if last != nil && fi.ModTime().Equal(last.ModTime()) && fi.Size() == last.Size() {
+					continue
+				}
+				last = fi
+				log.Printf("config: %s changed, reloading", path)
+				reload()
+			}
+		}
+	}()
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("targets: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloads := make(chan struct{}, 10)
+	Poll(ctx, path, 10*time.Millisecond, func() { reloads <- struct{}{} })
+
+	expect := func(what string, want bool) {
+		t.Helper()
+		select {
+		case <-reloads:
+			if !want {
+				t.Errorf("%s: unexpected reload", what)
+			}
+		case <-time.After(100 * time.Millisecond):
+			if want {
+				t.Errorf("%s: no reload", what)
+			}
+		}
+	}
+
+	expect("unchanged file", false)
+
+	if err := os.WriteFile(path, []byte("targets:\n  - name: a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expect("edited file", true)
+	expect("after the edit", false)
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	expect("file being replaced", false)
+	if err := os.WriteFile(path, []byte("targets:\n  - name: b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expect("replaced file", true)
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	expect("SIGHUP", true)
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusRange is an inclusive range of HTTP status codes (Min == Max for a single code).
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+// ExpectedStatus is the set of accepted status codes for a target.
+// In YAML it may be a single code or a list mixing codes and ranges:
+//
+//	expected_status: 200
+//	expected_status: [200, 204, "300-399"]
+//	expected_status: "2xx"
+//
+// An empty set means "any 2xx or 3xx".
+type ExpectedStatus []StatusRange
+
+func (e *ExpectedStatus) UnmarshalYAML(unmarshal func(any) error) error {
+	var raw any
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	var items []any
+	switch v := raw.(type) {
+	case nil:
+		*e = nil
+		return nil
+	case []any:
+		items = v
+	default:
+		items = []any{v}
+	}
+
+	out := make(ExpectedStatus, 0, len(items))
+	for _, item := range items {
+		r, err := parseStatusRange(fmt.Sprint(item))
+		if err != nil {
+			return err
+		}
+		out = append(out, r)
+	}
+	*e = out
+	return nil
+}
+
+// parseStatusRange accepts "200", "300-399" or "3xx".
+func parseStatusRange(s string) (StatusRange, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if len(s) == 3 && strings.HasSuffix(s, "xx") {
+		d, err := strconv.Atoi(s[:1])
+		if err != nil {
+			return StatusRange{}, fmt.Errorf("invalid status class %q", s)
+		}
+		return StatusRange{Min: d * 100, Max: d*100 + 99}, nil
+	}
+
+	if lo, hi, ok := strings.Cut(s, "-"); ok {
+		min, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		max, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil {
+			return StatusRange{}, fmt.Errorf("invalid status range %q", s)
+		}
+		return StatusRange{Min: min, Max: max}, nil
+	}
+
+	code, err := strconv.Atoi(s)
+	if err != nil {
+		return StatusRange{}, fmt.Errorf("invalid status code %q", s)
+	}
+	return StatusRange{Min: code, Max: code}, nil
+}
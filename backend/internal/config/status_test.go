@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestParseStatusRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    StatusRange
+		wantErr bool
+	}{
+		{in: "200", want: StatusRange{Min: 200, Max: 200}},
+		{in: " 204 ", want: StatusRange{Min: 204, Max: 204}},
+		{in: "300-399", want: StatusRange{Min: 300, Max: 399}},
+		{in: "200 - 204", want: StatusRange{Min: 200, Max: 204}},
+		{in: "2xx", want: StatusRange{Min: 200, Max: 299}},
+		{in: "5XX", want: StatusRange{Min: 500, Max: 599}},
+		{in: "ok", wantErr: true},
+		{in: "axx", wantErr: true},
+		{in: "200-", wantErr: true},
+		{in: "-300", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseStatusRange(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseStatusRange(%q) = %+v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseStatusRange(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseStatusRange(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExpectedStatusUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ExpectedStatus
+		wantErr bool
+	}{
+		{in: `expected_status: 200`, want: ExpectedStatus{{Min: 200, Max: 200}}},
+		{in: `expected_status: "2xx"`, want: ExpectedStatus{{Min: 200, Max: 299}}},
+		{in: `expected_status: [200, 204, "300-399"]`, want: ExpectedStatus{{200, 200}, {204, 204}, {300, 399}}},
+		{in: `expected_status: null`, want: nil},
+		{in: `expected_status: [200, "nope"]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var v struct {
+			ExpectedStatus ExpectedStatus `yaml:"expected_status"`
+		}
+		err := yaml.Unmarshal([]byte(tt.in), &v)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("unmarshal %q = %+v, want error", tt.in, v.ExpectedStatus)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unmarshal %q: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.ExpectedStatus, tt.want) {
+			t.Errorf("unmarshal %q = %+v, want %+v", tt.in, v.ExpectedStatus, tt.want)
+		}
+	}
+}
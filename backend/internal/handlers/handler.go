@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"cy-platforms-status-monitor/internal/monitor"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,7 +20,18 @@ func New(db *pgxpool.Pool) *Handler {
 	return &Handler{dbpool: db}
 }
 
-// GetUptime returns uptime stats for a target over a sliding window (default 24h).
+// probeParam returns the "probe" query parameter, defaulting to the central
+// instance's own probe.
+func probeParam(r *http.Request) string {
+	if p := strings.TrimSpace(r.URL.Query().Get("probe")); p != "" {
+		return p
+	}
+	return monitor.DefaultProbe
+}
+
+// GetUptime returns uptime stats for a target over a sliding window (default 24h),
+// as seen from one probe (default primary). Checks made during maintenance
+// windows are left out.
 func (h *Handler) GetUptime(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -39,16 +52,18 @@ func (h *Handler) GetUptime(w http.ResponseWriter, r *http.Request) {
 	}
 
 	from := time.Now().UTC().Add(-window)
+	probe := probeParam(r)
 
 	var total, up int64
 	err := h.dbpool.QueryRow(
 		r.Context(),
 		`SELECT 
 			COUNT(*) AS total,
-			COUNT(*) FILTER (WHERE status = 'UP') AS up
+			COUNT(*) FILTER (WHERE status IN ('UP', 'DEGRADED')) AS up
 		  FROM check_results
-		  WHERE target_name = $1 AND checked_at >= $2`,
-		target, from,
+		  WHERE target_name = $1 AND checked_at >= $2 AND probe = $3
+		    AND status <> 'MAINTENANCE'`,
+		target, from, probe,
 	).Scan(&total, &up)
 	if err != nil {
 		log.Printf("uptime query failed: %v", err)
@@ -63,6 +78,7 @@ func (h *Handler) GetUptime(w http.ResponseWriter, r *http.Request) {
 
 	resp := map[string]any{
 		"target":       target,
+		"probe":        probe,
 		"window":       window.String(),
 		"from":         from.Format(time.RFC3339),
 		"total_checks": total,
@@ -77,7 +93,8 @@ func (h *Handler) GetUptime(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GetUptimeAll returns uptime stats for all targets over a sliding window (default 24h).
+// GetUptimeAll returns uptime stats for all targets over a sliding window (default 24h),
+// as seen from one probe (default primary).
 func (h *Handler) GetUptimeAll(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -91,17 +108,18 @@ func (h *Handler) GetUptimeAll(w http.ResponseWriter, r *http.Request) {
 		window = d
 	}
 	from := time.Now().UTC().Add(-window)
+	probe := probeParam(r)
 
 	rows, err := h.dbpool.Query(
 		r.Context(),
 		`SELECT target_name,
 		        COUNT(*) AS total,
-		        COUNT(*) FILTER (WHERE status = 'UP') AS up
+		        COUNT(*) FILTER (WHERE status IN ('UP', 'DEGRADED')) AS up
 		   FROM check_results
-		  WHERE checked_at >= $1
+		  WHERE checked_at >= $1 AND probe = $2 AND status <> 'MAINTENANCE'
 		  GROUP BY target_name
 		  ORDER BY target_name`,
-		from,
+		from, probe,
 	)
 	if err != nil {
 		log.Printf("uptime all query failed: %v", err)
@@ -152,6 +170,7 @@ func (h *Handler) GetUptimeAll(w http.ResponseWriter, r *http.Request) {
 
 	resp := map[string]any{
 		"generated_at": time.Now().UTC().Format(time.RFC3339),
+		"probe":        probe,
 		"window":       window.String(),
 		"from":         from.Format(time.RFC3339),
 		"items":        list,
@@ -162,3 +181,92 @@ func (h *Handler) GetUptimeAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// latencyPhases are the check_results columns reported by GetLatency, in request order.
+var latencyPhases = []string{"dns_ms", "connect_ms", "tls_ms", "ttfb_ms", "transfer_ms", "latency_ms"}
+
+// GetLatency returns a per-phase latency breakdown (avg/p50/p95/max) for an HTTP
+// target over a sliding window (default 24h), as seen from one probe (default
+// primary). Only checks that recorded phase timings are included.
+func (h *Handler) GetLatency(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	target := strings.TrimSpace(r.URL.Query().Get("target"))
+	if target == "" {
+		http.Error(w, "missing target", http.StatusBadRequest)
+		return
+	}
+
+	window := 24 * time.Hour
+	if raw := strings.TrimSpace(r.URL.Query().Get("window")); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid window duration", http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+
+	from := time.Now().UTC().Add(-window)
+	probe := probeParam(r)
+
+	type stats struct {
+		AvgMs float64 `json:"avg_ms"`
+		P50Ms float64 `json:"p50_ms"`
+		P95Ms float64 `json:"p95_ms"`
+		MaxMs float64 `json:"max_ms"`
+	}
+
+	// One pass over the window: COUNT(*) then avg/p50/p95/max per phase.
+	// Columns come from latencyPhases, never from the request.
+	aggs := make([]string, 0, len(latencyPhases))
+	for _, col := range latencyPhases {
+		aggs = append(aggs,
+			`COALESCE(AVG(`+col+`), 0)`,
+			`COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY `+col+`), 0)`,
+			`COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY `+col+`), 0)`,
+			`COALESCE(MAX(`+col+`), 0)`,
+		)
+	}
+
+	var samples int64
+	all := make([]stats, len(latencyPhases))
+	dest := []any{&samples}
+	for i := range all {
+		dest = append(dest, &all[i].AvgMs, &all[i].P50Ms, &all[i].P95Ms, &all[i].MaxMs)
+	}
+
+	err := h.dbpool.QueryRow(
+		r.Context(),
+		`SELECT COUNT(*), `+strings.Join(aggs, ", ")+`
+		   FROM check_results
+		  WHERE target_name = $1 AND checked_at >= $2 AND probe = $3 AND ttfb_ms IS NOT NULL
+		    AND status <> 'MAINTENANCE'`,
+		target, from, probe,
+	).Scan(dest...)
+	if err != nil {
+		log.Printf("latency query failed: %v", err)
+		http.Error(w, "latency query failed", http.StatusInternalServerError)
+		return
+	}
+
+	phases := make(map[string]stats, len(latencyPhases))
+	for i, col := range latencyPhases {
+		phases[strings.TrimSuffix(col, "_ms")] = all[i]
+	}
+
+	resp := map[string]any{
+		"target":       target,
+		"probe":        probe,
+		"window":       window.String(),
+		"from":         from.Format(time.RFC3339),
+		"samples":      samples,
+		"phases":       phases,
+		"generated_at": time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "failed to encode latency", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"cy-platforms-status-monitor/internal/agent"
+	"cy-platforms-status-monitor/internal/maintenance"
+	"cy-platforms-status-monitor/internal/monitor"
+)
+
+// maxIngestBytes bounds one agent batch.
+const maxIngestBytes = 8 << 20
+
+var probeNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Ingest accepts result batches from remote probe agents (see package agent)
+// and feeds them to the Aggregator under the agent's probe name. Only enabled
+// targets of the central config (see monitor.PublishTargets) are accepted;
+// their alert policy and maintenance windows come from the central side, not
+// from the agent. Results older than the target's quorum window, replayed by
+// an agent after an outage, are rejected: they would raise alerts for
+// transitions long past.
+func Ingest(resultsCh chan<- monitor.CheckResult) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var batch agent.Batch
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxIngestBytes)).Decode(&batch); err != nil {
+			http.Error(w, "invalid batch: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !probeNameRe.MatchString(batch.Probe) || batch.Probe == monitor.DefaultProbe {
+			http.Error(w, "invalid probe name", http.StatusBadRequest)
+			return
+		}
+
+		// Validate the whole batch before handing anything over: once results
+		// reach the aggregator they are stored, so a partial hand-off followed
+		// by an agent retry would record them twice.
+		accepted := make([]monitor.CheckResult, 0, len(batch.Results))
+		var rejected int
+		for _, res := range batch.Results {
+			t, ok := monitor.LookupTarget(res.TargetName)
+			if !ok || res.At.IsZero() || stale(res, t) {
+				rejected++
+				continue
+			}
+
+			res.Probe = batch.Probe
+			res.URL = t.URL
+			res.Alert = t.Alert
+			res.Maintenance = nil
+			if window, ok := maintenance.ActiveFor(t.Name, t.Tags, res.At); ok {
+				res.Maintenance = &window
+			}
+			accepted = append(accepted, res)
+		}
+
+		// Deliberately not cancelled with the request: the batch is either
+		// handed over in full or, if rejected above, not at all.
+		for _, res := range accepted {
+			resultsCh <- res
+		}
+		if rejected > 0 {
+			log.Printf("ingest: probe %s: rejected %d of %d results", batch.Probe, rejected, len(batch.Results))
+		}
+
+		if err := json.NewEncoder(w).Encode(map[string]int{"accepted": len(accepted), "rejected": rejected}); err != nil {
+			http.Error(w, "failed to encode ingest result", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// stale reports whether res is too old to count towards t's current state.
+func stale(res monitor.CheckResult, t monitor.Target) bool {
+	return t.Alert.QuorumWindow > 0 && time.Since(res.At) > t.Alert.QuorumWindow
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cy-platforms-status-monitor/internal/agent"
+	"cy-platforms-status-monitor/internal/monitor"
+)
+
+func postBatch(h http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, agent.IngestPath, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIngest(t *testing.T) {
+	policy := monitor.AlertPolicy{DownAfter: 3, QuorumWindow: 5 * time.Minute}
+	monitor.PublishTargets([]monitor.Target{
+		{Name: "web", URL: "https://example.com", Enabled: true, Alert: policy},
+		{Name: "off", URL: "https://off.example.com"},
+	})
+	resultsCh := make(chan monitor.CheckResult, 10)
+	h := Ingest(resultsCh)
+
+	now := time.Now().UTC()
+	batch, _ := json.Marshal(agent.Batch{Probe: "fra", Results: []monitor.CheckResult{
+		{TargetName: "web", URL: "https://agent.example", Probe: "primary", At: now, Up: true},
+		{TargetName: "web", At: now.Add(time.Second)},
+		{TargetName: "off", At: now},
+		{TargetName: "unknown", At: now},
+		{TargetName: "web"},
+		{TargetName: "web", At: now.Add(-time.Hour)},
+	}})
+	rec := postBatch(h, string(batch))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var got map[string]int
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["accepted"] != 2 || got["rejected"] != 4 {
+		t.Errorf("response %v, want 2 accepted, 4 rejected", got)
+	}
+
+	if len(resultsCh) != 2 {
+		t.Fatalf("%d results handed over, want 2", len(resultsCh))
+	}
+	for range 2 {
+		res := <-resultsCh
+		if res.Probe != "fra" || res.URL != "https://example.com" || res.Alert.DownAfter != policy.DownAfter {
+			t.Errorf("result probe %q url %q alert %+v; want the agent's probe and the central target", res.Probe, res.URL, res.Alert)
+		}
+	}
+}
+
+func TestIngestHandsOverInFull(t *testing.T) {
+	monitor.PublishTargets([]monitor.Target{{Name: "web", URL: "https://example.com", Enabled: true}})
+	resultsCh := make(chan monitor.CheckResult)
+	got := make(chan int)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		n := 0
+		for range resultsCh {
+			n++
+		}
+		got <- n
+	}()
+
+	// The agent gives up on the request before the aggregator has room.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	now := time.Now()
+	batch, _ := json.Marshal(agent.Batch{Probe: "fra", Results: []monitor.CheckResult{
+		{TargetName: "web", At: now}, {TargetName: "web", At: now}, {TargetName: "web", At: now},
+	}})
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, agent.IngestPath, bytes.NewReader(batch))
+	Ingest(resultsCh).ServeHTTP(httptest.NewRecorder(), req)
+	close(resultsCh)
+
+	if n := <-got; n != 3 {
+		t.Errorf("%d of 3 results handed over, want the whole batch", n)
+	}
+}
+
+func TestIngestRejectsBatch(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "not json", body: "results"},
+		{name: "no probe", body: `{"results": []}`},
+		{name: "invalid probe", body: `{"probe": "Frankfurt DE", "results": []}`},
+		{name: "central probe", body: `{"probe": "` + monitor.DefaultProbe + `", "results": []}`},
+	}
+
+	resultsCh := make(chan monitor.CheckResult, 10)
+	h := Ingest(resultsCh)
+	for _, tt := range tests {
+		if rec := postBatch(h, tt.body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", tt.name, rec.Code)
+		}
+	}
+	if len(resultsCh) != 0 {
+		t.Errorf("%d results handed over from rejected batches", len(resultsCh))
+	}
+}
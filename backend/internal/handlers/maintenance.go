@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cy-platforms-status-monitor/internal/maintenance"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maintenanceRequest is the body of POST /maintenance. Times are RFC3339, or
+// "2006-01-02 15:04" read as Europe/Nicosia local time.
+type maintenanceRequest struct {
+	Target          string `json:"target"`
+	Tag             string `json:"tag"`
+	StartsAt        string `json:"starts_at"`
+	EndsAt          string `json:"ends_at"`
+	Cron            string `json:"cron"`
+	DurationMinutes int    `json:"duration_minutes"`
+	Reason          string `json:"reason"`
+}
+
+// RequireToken guards write endpoints with "Authorization: Bearer <token>".
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ListMaintenance returns every maintenance window, newest first.
+func (h *Handler) ListMaintenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	list, err := maintenance.List(r.Context(), h.dbpool)
+	if err != nil {
+		log.Printf("maintenance list failed: %v", err)
+		http.Error(w, "maintenance query failed", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(map[string]any{"items": list}); err != nil {
+		http.Error(w, "failed to encode maintenance", http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreateMaintenance stores a new window and applies it immediately.
+func (h *Handler) CreateMaintenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req maintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+
+	win := maintenance.Window{
+		TargetName:      req.Target,
+		Tag:             req.Tag,
+		Cron:            strings.TrimSpace(req.Cron),
+		DurationMinutes: req.DurationMinutes,
+		Reason:          strings.TrimSpace(req.Reason),
+	}
+	for _, f := range []struct {
+		raw string
+		dst **time.Time
+	}{{req.StartsAt, &win.StartsAt}, {req.EndsAt, &win.EndsAt}} {
+		if strings.TrimSpace(f.raw) == "" {
+			continue
+		}
+		t, err := parseMaintenanceTime(f.raw)
+		if err != nil {
+			http.Error(w, "invalid time "+strconv.Quote(f.raw)+": use RFC3339 or 2006-01-02 15:04", http.StatusBadRequest)
+			return
+		}
+		*f.dst = &t
+	}
+
+	if err := win.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := maintenance.Create(r.Context(), h.dbpool, &win); err != nil {
+		log.Printf("maintenance create failed: %v", err)
+		http.Error(w, "maintenance insert failed", http.StatusInternalServerError)
+		return
+	}
+	if err := maintenance.Reload(r.Context(), h.dbpool); err != nil {
+		log.Printf("maintenance reload failed: %v", err)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(win); err != nil {
+		http.Error(w, "failed to encode maintenance", http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteMaintenance removes a window by id.
+func (h *Handler) DeleteMaintenance(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	found, err := maintenance.Delete(r.Context(), h.dbpool, id)
+	if err != nil {
+		log.Printf("maintenance delete failed: %v", err)
+		http.Error(w, "maintenance delete failed", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err := maintenance.Reload(r.Context(), h.dbpool); err != nil {
+		log.Printf("maintenance reload failed: %v", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseMaintenanceTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04", raw, maintenance.Zone)
+}
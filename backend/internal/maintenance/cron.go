@@ -0,0 +1,166 @@
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSpec is a parsed 5-field cron expression: minute hour day-of-month month day-of-week.
+// Fields accept *, numbers, ranges (1-5), lists (1,3,5) and steps (*/15, 0-30/10).
+// Day-of-week is 0-6 with 0 = Sunday (7 is accepted as Sunday too).
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64 // bit sets
+	domAny, dowAny                bool
+}
+
+func parseCron(expr string) (*cronSpec, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday)", expr)
+	}
+
+	var (
+		c   cronSpec
+		err error
+	)
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron %q minute: %w", expr, err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron %q hour: %w", expr, err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron %q day: %w", expr, err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron %q month: %w", expr, err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron %q weekday: %w", expr, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 = Sunday
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return &c, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if step > 1 && !isRange {
+				hi = max // "5/10" means 5,15,25,... like standard cron
+			}
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// matches reports whether t (already in the schedule's zone) is a start minute.
+// Like standard cron, when both day fields are restricted either may match.
+func (c *cronSpec) matches(t time.Time) bool {
+	return c.minute&(1<<uint(t.Minute())) != 0 &&
+		c.hour&(1<<uint(t.Hour())) != 0 &&
+		c.dayMatches(t)
+}
+
+// dayMatches reports whether the calendar day of t can hold a start minute.
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	if c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domOK := c.dom&(1<<uint(t.Day())) != 0
+	dowOK := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dowOK
+	case c.dowAny:
+		return domOK
+	default:
+		return domOK || dowOK
+	}
+}
+
+// lastStart returns the most recent start minute in (at-lookback, at], if any.
+// It walks back a day at a time and picks the latest matching hour and minute
+// within each day, so the cost is bounded by the days in the lookback rather
+// than its minutes.
+func (c *cronSpec) lastStart(at time.Time, lookback time.Duration) (time.Time, bool) {
+	earliest := at.Add(-lookback)
+	loc := at.Location()
+	at = at.Truncate(time.Minute)
+
+	maxHour, maxMinute := at.Hour(), at.Minute()
+	for y, m, d := at.Date(); ; d-- {
+		day := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		if day.AddDate(0, 0, 1).Before(earliest) {
+			return time.Time{}, false
+		}
+
+		if c.dayMatches(day) {
+			for h := maxHour; h >= 0; h-- {
+				if c.hour&(1<<uint(h)) == 0 {
+					continue
+				}
+				lastMinute := 59
+				if h == maxHour {
+					lastMinute = maxMinute
+				}
+				for min := lastMinute; min >= 0; min-- {
+					if c.minute&(1<<uint(min)) == 0 {
+						continue
+					}
+					t := time.Date(y, m, d, h, min, 0, 0, loc)
+					if t.Hour() != h || t.Minute() != min {
+						continue // wall time skipped by a DST change
+					}
+					if t.After(at) {
+						// A wall time repeated by a DST change may have an
+						// earlier occurrence an hour before.
+						if t = t.Add(-time.Hour); t.Hour() != h || t.Minute() != min || t.After(at) {
+							continue
+						}
+					}
+					if !t.After(earliest) {
+						return time.Time{}, false
+					}
+					return t, true
+				}
+			}
+		}
+		maxHour, maxMinute = 23, 59
+	}
+}
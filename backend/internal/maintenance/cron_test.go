@@ -0,0 +1,179 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		want     []int
+		wantErr  bool
+	}{
+		{field: "*", min: 0, max: 5, want: []int{0, 1, 2, 3, 4, 5}},
+		{field: "3", min: 0, max: 59, want: []int{3}},
+		{field: "1-3", min: 0, max: 59, want: []int{1, 2, 3}},
+		{field: "1,4,6", min: 0, max: 59, want: []int{1, 4, 6}},
+		{field: "*/15", min: 0, max: 59, want: []int{0, 15, 30, 45}},
+		{field: "0-30/10", min: 0, max: 59, want: []int{0, 10, 20, 30}},
+		{field: "5/10", min: 0, max: 59, want: []int{5, 15, 25, 35, 45, 55}},
+		{field: "1/2", min: 1, max: 7, want: []int{1, 3, 5, 7}},
+		{field: "60", min: 0, max: 59, wantErr: true},
+		{field: "5-1", min: 0, max: 59, wantErr: true},
+		{field: "*/0", min: 0, max: 59, wantErr: true},
+		{field: "a", min: 0, max: 59, wantErr: true},
+		{field: "1-b", min: 0, max: 59, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		bits, err := parseCronField(tt.field, tt.min, tt.max)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseCronField(%q) = %b, want error", tt.field, bits)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseCronField(%q): %v", tt.field, err)
+			continue
+		}
+		var want uint64
+		for _, v := range tt.want {
+			want |= 1 << uint(v)
+		}
+		if bits != want {
+			t.Errorf("parseCronField(%q) = %b, want %b", tt.field, bits, want)
+		}
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "0 2 * * 0"},
+		{expr: "*/5 * * * *"},
+		{expr: "30 1 1,15 * 7"},
+		{expr: "0 2 * *", wantErr: true},
+		{expr: "0 24 * * *", wantErr: true},
+		{expr: "0 0 0 * *", wantErr: true},
+		{expr: "0 0 * 13 *", wantErr: true},
+		{expr: "0 0 * * 8", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := parseCron(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCron(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+		}
+	}
+
+	c, err := parseCron("0 0 * * 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.dow&1 == 0 {
+		t.Errorf("weekday 7 should also match Sunday (0)")
+	}
+}
+
+func TestCronMatchesDayFields(t *testing.T) {
+	// 2026-10-15 is a Thursday.
+	thu15 := time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)
+	fri16 := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{expr: "0 2 * * *", at: thu15, want: true},
+		{expr: "0 2 15 * *", at: thu15, want: true},
+		{expr: "0 2 15 * *", at: fri16, want: false},
+		{expr: "0 2 * * 4", at: thu15, want: true},
+		{expr: "0 2 * * 4", at: fri16, want: false},
+		// Both day fields restricted: either may match.
+		{expr: "0 2 1 * 5", at: fri16, want: true},
+		{expr: "0 2 15 * 5", at: thu15, want: true},
+		{expr: "0 2 1 * 1", at: thu15, want: false},
+		{expr: "0 3 * * *", at: thu15, want: false},
+		{expr: "0 2 * 11 *", at: thu15, want: false},
+	}
+
+	for _, tt := range tests {
+		c, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		if got := c.matches(tt.at); got != tt.want {
+			t.Errorf("%q matches(%s) = %v, want %v", tt.expr, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestCronLastStart(t *testing.T) {
+	at := time.Date(2026, 10, 15, 2, 30, 45, 0, Zone) // Thursday
+
+	tests := []struct {
+		expr     string
+		lookback time.Duration
+		want     time.Time // zero: no start in the lookback
+	}{
+		{expr: "0 2 * * *", lookback: time.Hour, want: time.Date(2026, 10, 15, 2, 0, 0, 0, Zone)},
+		{expr: "30 2 * * *", lookback: time.Minute, want: time.Date(2026, 10, 15, 2, 30, 0, 0, Zone)},
+		{expr: "31 2 * * *", lookback: time.Hour, want: time.Time{}},
+		{expr: "0 2 * * *", lookback: 30 * time.Minute, want: time.Time{}},
+		{expr: "5/10 * * * *", lookback: time.Hour, want: time.Date(2026, 10, 15, 2, 25, 0, 0, Zone)},
+		{expr: "0 23 * * *", lookback: 4 * time.Hour, want: time.Date(2026, 10, 14, 23, 0, 0, 0, Zone)},
+		{expr: "0 23 * * *", lookback: 3 * time.Hour, want: time.Time{}},
+		{expr: "0 2 * * 0", lookback: 7 * 24 * time.Hour, want: time.Date(2026, 10, 11, 2, 0, 0, 0, Zone)},
+		{expr: "0 0 1 * *", lookback: 20 * 24 * time.Hour, want: time.Date(2026, 10, 1, 0, 0, 0, 0, Zone)},
+		{expr: "0 0 1 * *", lookback: 10 * 24 * time.Hour, want: time.Time{}},
+		{expr: "45 23 30 9 *", lookback: 16 * 24 * time.Hour, want: time.Date(2026, 9, 30, 23, 45, 0, 0, Zone)},
+	}
+
+	for _, tt := range tests {
+		c, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		got, ok := c.lastStart(at, tt.lookback)
+		if ok != !tt.want.IsZero() || !got.Equal(tt.want) {
+			t.Errorf("%q lastStart(%s, %s) = %s, %v; want %s", tt.expr, at, tt.lookback, got, ok, tt.want)
+		}
+	}
+}
+
+// TestCronLastStartMatchesScan checks lastStart against a minute-by-minute
+// scan, across the Nicosia DST change on 2026-10-25.
+func TestCronLastStartMatchesScan(t *testing.T) {
+	exprs := []string{"0 2 * * 0", "*/7 3 * * *", "15 0-6/2 * * 1-5", "0 0 1,25 * *"}
+	lookback := 36 * time.Hour
+
+	for _, expr := range exprs {
+		c, err := parseCron(expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", expr, err)
+		}
+		for at := time.Date(2026, 10, 23, 0, 0, 0, 0, Zone); at.Before(time.Date(2026, 10, 27, 0, 0, 0, 0, Zone)); at = at.Add(17 * time.Minute) {
+			want, wantOK := scanLastStart(c, at, lookback)
+			got, ok := c.lastStart(at, lookback)
+			if ok != wantOK || !got.Equal(want) {
+				t.Errorf("%q lastStart(%s) = %s, %v; scan found %s, %v", expr, at, got, ok, want, wantOK)
+			}
+		}
+	}
+}
+
+func scanLastStart(c *cronSpec, at time.Time, lookback time.Duration) (time.Time, bool) {
+	t := at.Truncate(time.Minute)
+	for earliest := at.Add(-lookback); t.After(earliest); t = t.Add(-time.Minute) {
+		if c.matches(t) {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
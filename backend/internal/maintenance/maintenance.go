@@ -0,0 +1,221 @@
+// Package maintenance holds planned-downtime windows. Results that fall inside
+// an active window are stored as MAINTENANCE, open no incidents, send no
+// notifications and are left out of uptime.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+	_ "time/tzdata" // Europe/Nicosia must resolve in minimal containers
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Zone is the time zone recurring schedules and local times are written in.
+var Zone = mustLoadLocation("Europe/Nicosia")
+
+// MaxRecurringDuration bounds how long a recurring window may last.
+const MaxRecurringDuration = 24 * time.Hour
+
+// Window is one maintenance window for a target or for every target with a tag.
+// It is either one-off (StartsAt/EndsAt) or recurring (Cron + DurationMinutes).
+type Window struct {
+	ID              int64      `json:"id"`
+	TargetName      string     `json:"target,omitempty"`
+	Tag             string     `json:"tag,omitempty"`
+	StartsAt        *time.Time `json:"starts_at,omitempty"`
+	EndsAt          *time.Time `json:"ends_at,omitempty"`
+	Cron            string     `json:"cron,omitempty"`             // start times, e.g. "0 2 * * 0" (Sundays 02:00 Nicosia time)
+	DurationMinutes int        `json:"duration_minutes,omitempty"` // length of each recurring occurrence
+	Reason          string     `json:"reason,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+
+	cron *cronSpec
+}
+
+// Validate checks the window and compiles its cron expression.
+func (w *Window) Validate() error {
+	w.TargetName = strings.TrimSpace(w.TargetName)
+	w.Tag = strings.TrimSpace(w.Tag)
+	if (w.TargetName == "") == (w.Tag == "") {
+		return errors.New("maintenance: set exactly one of target or tag")
+	}
+
+	oneOff := w.StartsAt != nil || w.EndsAt != nil
+	recurring := strings.TrimSpace(w.Cron) != ""
+	switch {
+	case oneOff && recurring:
+		return errors.New("maintenance: set starts_at/ends_at or cron, not both")
+	case oneOff:
+		if w.StartsAt == nil || w.EndsAt == nil || !w.EndsAt.After(*w.StartsAt) {
+			return errors.New("maintenance: ends_at must be after starts_at")
+		}
+	case recurring:
+		spec, err := parseCron(w.Cron)
+		if err != nil {
+			return fmt.Errorf("maintenance: %w", err)
+		}
+		if d := w.duration(); d <= 0 || d > MaxRecurringDuration {
+			return fmt.Errorf("maintenance: recurring duration must be > 0 and at most %s", MaxRecurringDuration)
+		}
+		w.cron = spec
+	default:
+		return errors.New("maintenance: set starts_at/ends_at or cron")
+	}
+	return nil
+}
+
+// appliesTo reports whether the window covers a target with the given tags.
+func (w *Window) appliesTo(target string, tags []string) bool {
+	if w.TargetName != "" {
+		return w.TargetName == target
+	}
+	return slices.Contains(tags, w.Tag)
+}
+
+// activeAt reports whether the window covers at, returning when that occurrence ends.
+func (w *Window) activeAt(at time.Time) (time.Time, bool) {
+	if w.cron == nil {
+		if w.StartsAt == nil || w.EndsAt == nil {
+			return time.Time{}, false
+		}
+		return *w.EndsAt, !at.Before(*w.StartsAt) && at.Before(*w.EndsAt)
+	}
+	start, ok := w.cron.lastStart(at.In(Zone), w.duration())
+	if !ok {
+		return time.Time{}, false
+	}
+	return start.Add(w.duration()), true
+}
+
+func (w *Window) duration() time.Duration {
+	return time.Duration(w.DurationMinutes) * time.Minute
+}
+
+// Active describes the window currently covering a target.
+type Active struct {
+	WindowID int64
+	Reason   string
+	Until    time.Time
+}
+
+var current atomic.Value // stores []Window
+
+// Publish replaces the windows consulted by ActiveFor.
+func Publish(ws []Window) {
+	current.Store(ws)
+}
+
+// ActiveFor returns the window covering target (or any of its tags) at time at.
+func ActiveFor(target string, tags []string, at time.Time) (Active, bool) {
+	ws, _ := current.Load().([]Window)
+	for i := range ws {
+		w := &ws[i]
+		if !w.appliesTo(target, tags) {
+			continue
+		}
+		if until, ok := w.activeAt(at); ok {
+			return Active{WindowID: w.ID, Reason: w.Reason, Until: until}, true
+		}
+	}
+	return Active{}, false
+}
+
+// List returns every stored window, newest first.
+func List(ctx context.Context, db *pgxpool.Pool) ([]Window, error) {
+	rows, err := db.Query(ctx, `
+		SELECT id, COALESCE(target_name, ''), COALESCE(tag, ''), starts_at, ends_at,
+		       COALESCE(cron, ''), COALESCE(duration_minutes, 0), COALESCE(reason, ''), created_at
+		  FROM maintenance_windows
+		 ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Window, 0)
+	for rows.Next() {
+		var w Window
+		if err := rows.Scan(&w.ID, &w.TargetName, &w.Tag, &w.StartsAt, &w.EndsAt, &w.Cron, &w.DurationMinutes, &w.Reason, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := w.Validate(); err != nil {
+			log.Printf("maintenance: skipping window %d: %v", w.ID, err)
+			continue
+		}
+		list = append(list, w)
+	}
+	return list, rows.Err()
+}
+
+// Create validates and stores w, filling ID and CreatedAt.
+func Create(ctx context.Context, db *pgxpool.Pool, w *Window) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	var minutes *int
+	if w.cron != nil {
+		minutes = &w.DurationMinutes
+	}
+	return db.QueryRow(ctx, `
+		INSERT INTO maintenance_windows (target_name, tag, starts_at, ends_at, cron, duration_minutes, reason)
+		VALUES (NULLIF($1, ''), NULLIF($2, ''), $3, $4, NULLIF($5, ''), $6, NULLIF($7, ''))
+		RETURNING id, created_at`,
+		w.TargetName, w.Tag, w.StartsAt, w.EndsAt, w.Cron, minutes, w.Reason,
+	).Scan(&w.ID, &w.CreatedAt)
+}
+
+// Delete removes a window. It reports false when no window had that id.
+func Delete(ctx context.Context, db *pgxpool.Pool, id int64) (bool, error) {
+	tag, err := db.Exec(ctx, `DELETE FROM maintenance_windows WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Reload publishes the windows currently stored in the database.
+func Reload(ctx context.Context, db *pgxpool.Pool) error {
+	ws, err := List(ctx, db)
+	if err != nil {
+		return err
+	}
+	Publish(ws)
+	return nil
+}
+
+// StartRefresher reloads windows every interval so edits made directly in the
+// database are picked up. API writes call Reload themselves.
+func StartRefresher(ctx context.Context, db *pgxpool.Pool, interval time.Duration) {
+	if err := Reload(ctx, db); err != nil {
+		log.Printf("maintenance: initial load failed: %v", err)
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if err := Reload(ctx, db); err != nil {
+					log.Printf("maintenance: reload failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
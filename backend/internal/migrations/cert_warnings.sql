@@ -0,0 +1,10 @@
+-- Certificate expiry warnings already sent, per target and probe, so a restart
+-- doesn't warn again about the same certificate. Targets with a quorum are
+-- warned about once, under probe 'quorum'.
+create table if not exists cert_warnings (
+    target_name text not null,
+    probe text not null default 'primary',
+    not_after timestamptz not null,
+    warned_at timestamptz not null default now(),
+    primary key (target_name, probe)
+);
@@ -2,11 +2,20 @@ create table check_results (
     id bigserial primary key,
     target_name text not null,
     checked_at timestamptz not null default now(),
-    status text not null, -- UP / DOWN / TIMEOUT / BLOCKED
+    status text not null, -- UP / DEGRADED / DOWN / TIMEOUT / BLOCKED
     status_code integer,
     latency_ms integer,
     error text,
-    probe text not null default 'primary'
+    probe text not null default 'primary',
+    attempt integer not null default 1, -- attempt that produced this row (>1 after retries)
+    attempts jsonb, -- every attempt of a retried check, in order (NULL without retries)
+    confirmed_status text, -- UP / DEGRADED / DOWN after down_after/up_after (NULL for MAINTENANCE)
+    -- HTTP phase breakdown (NULL for non-HTTP checks)
+    dns_ms integer,
+    connect_ms integer,
+    tls_ms integer,
+    ttfb_ms integer,
+    transfer_ms integer
 );
 
 create index idx_check_results_target_time
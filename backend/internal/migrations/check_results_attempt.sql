@@ -0,0 +1,3 @@
+-- Upgrade for databases created before check_results.attempt existed.
+alter table check_results
+  add column if not exists attempt integer not null default 1;
@@ -0,0 +1,3 @@
+-- Upgrade for databases created before check_results.attempts existed.
+alter table check_results
+  add column if not exists attempts jsonb;
@@ -0,0 +1,4 @@
+-- Upgrade for databases created before check_results.confirmed_status existed.
+-- Older rows keep NULL; state is then hydrated from their raw status.
+alter table check_results
+  add column if not exists confirmed_status text;
@@ -0,0 +1,7 @@
+-- Upgrade for databases created before the HTTP phase timing columns existed.
+alter table check_results
+  add column if not exists dns_ms integer,
+  add column if not exists connect_ms integer,
+  add column if not exists tls_ms integer,
+  add column if not exists ttfb_ms integer,
+  add column if not exists transfer_ms integer;
@@ -0,0 +1,10 @@
+-- Last watched content per target and probe (targets with watch_content).
+-- Targets with a quorum keep one snapshot, under probe 'quorum'.
+create table if not exists content_snapshots (
+    target_name text not null,
+    probe text not null default 'primary',
+    hash text not null,
+    content text not null,
+    updated_at timestamptz not null default now(),
+    primary key (target_name, probe)
+);
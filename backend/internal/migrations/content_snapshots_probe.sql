@@ -0,0 +1,6 @@
+-- Upgrade for databases created before content_snapshots was keyed by probe.
+alter table content_snapshots
+  add column if not exists probe text not null default 'primary';
+
+alter table content_snapshots drop constraint if exists content_snapshots_pkey;
+alter table content_snapshots add primary key (target_name, probe);
@@ -2,6 +2,7 @@ create table incidents (
   id bigserial primary key,
   target_name text not null,
   probe text not null default 'primary',
+  kind text not null default 'DOWN',   -- DOWN / DEGRADED / FLAPPING
 
   started_at timestamptz not null,
   ended_at timestamptz,
@@ -9,8 +10,10 @@ create table incidents (
   start_status text not null,         -- DOWN / TIMEOUT / BLOCKED
   start_status_code integer,
   start_error text,
+  caused_by text,                     -- parent target (depends_on) that was DOWN when this opened
+  probes text[],                      -- probe = 'quorum': probes that saw the target DOWN
 
-  end_status text,                    -- usually UP
+  end_status text,                    -- usually UP; REMOVED when the target left the config
   end_status_code integer,
   end_error text,
 
@@ -27,7 +30,7 @@ create index idx_incidents_active
 on incidents (ended_at)
 where ended_at is null;
 
--- Enforce only ONE active incident per (target_name, probe)
+-- Enforce only ONE active incident per (target_name, probe, kind)
 create unique index uq_incidents_one_active
-on incidents (target_name, probe)
+on incidents (target_name, probe, kind)
 where ended_at is null;
\ No newline at end of file
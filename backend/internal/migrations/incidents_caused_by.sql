@@ -0,0 +1,3 @@
+-- Upgrade for databases created before incidents.caused_by existed.
+alter table incidents
+  add column if not exists caused_by text;
@@ -0,0 +1,9 @@
+-- Upgrade for databases created before incidents.kind existed.
+alter table incidents
+  add column if not exists kind text not null default 'DOWN';
+
+drop index if exists uq_incidents_one_active;
+
+create unique index uq_incidents_one_active
+on incidents (target_name, probe, kind)
+where ended_at is null;
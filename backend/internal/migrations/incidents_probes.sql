@@ -0,0 +1,3 @@
+-- Upgrade for databases created before incidents.probes existed.
+alter table incidents
+  add column if not exists probes text[];
@@ -0,0 +1,23 @@
+-- Planned maintenance. A window covers one target or every target with a tag,
+-- and is either one-off (starts_at/ends_at) or recurring (cron + duration_minutes,
+-- evaluated in Europe/Nicosia time).
+create table if not exists maintenance_windows (
+  id bigserial primary key,
+  target_name text,
+  tag text,
+
+  starts_at timestamptz,
+  ends_at timestamptz,
+
+  cron text,                -- e.g. '0 2 * * 0' = Sundays 02:00
+  duration_minutes integer,
+
+  reason text,
+  created_at timestamptz not null default now(),
+
+  check ((target_name is null) <> (tag is null)),
+  check (
+    (cron is null and starts_at is not null and ends_at > starts_at)
+    or (cron is not null and starts_at is null and ends_at is null and duration_minutes > 0)
+  )
+);
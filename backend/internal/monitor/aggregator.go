@@ -3,6 +3,7 @@ package monitor
 import (
 	"context"
 	"cy-platforms-status-monitor/internal/snapshot"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -13,8 +14,19 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// stateKey identifies a State: the same target checked from two probes is
+// tracked (and alerted on) independently.
+type stateKey struct {
+	Target string
+	Probe  string
+}
+
 func Aggregator(ctx context.Context, resCh <-chan CheckResult, eventsCh chan<- Event, db *pgxpool.Pool) {
-	state := make(map[string]*State)
+	state := make(map[stateKey]*State)
+	// Cross-probe decisions, only for targets with a quorum (see evaluateQuorum).
+	quorums := make(map[string]*quorumState)
+	// Generation of the published target set the maps were last pruned against.
+	var targetsSeen uint64
 
 	for {
 		select {
@@ -26,50 +38,205 @@ func Aggregator(ctx context.Context, resCh <-chan CheckResult, eventsCh chan<- E
 				return
 			}
 
-			if db != nil {
-				_ = persistCheckResult(ctx, db, res) // best-effort; ignore error for now
+			if res.Probe == "" {
+				res.Probe = DefaultProbe
+			}
+
+			// After a config reload: forget removed targets, and ignore their
+			// checks that were still in flight.
+			if ts := currentTargets.Load(); ts != nil {
+				if ts.gen != targetsSeen {
+					pruneStates(state, quorums, ts)
+					if db != nil {
+						closeRemovedIncidents(ctx, db, ts)
+					}
+					targetsSeen = ts.gen
+					snapshot.Publish(buildSnapshot(state))
+				}
+				if _, ok := ts.byName[res.TargetName]; !ok {
+					continue
+				}
 			}
 
-			st := state[res.TargetName]
+			key := stateKey{Target: res.TargetName, Probe: res.Probe}
+			st := state[key]
 			if st == nil {
 				// Try to hydrate from DB so we keep streaks across restarts.
-				loaded, err := loadStateFromDB(ctx, db, res.TargetName)
+				loaded, err := loadStateFromDB(ctx, db, res.TargetName, res.Probe, res.Alert)
 				if err != nil && !errorsIsContextDeadline(err) {
-					log.Printf("aggregator: fallback to empty state for %s: %v", res.TargetName, err)
+					log.Printf("aggregator: fallback to empty state for %s@%s: %v", res.TargetName, res.Probe, err)
 				}
 				if loaded == nil {
-					loaded = &State{Name: res.TargetName, URL: res.URL}
+					loaded = &State{Name: res.TargetName, URL: res.URL, Probe: res.Probe}
 				}
 
-				state[res.TargetName] = loaded
+				if db != nil {
+					content, err := loadContentSnapshot(ctx, db, res.TargetName, res.Probe)
+					if err != nil {
+						log.Printf("aggregator: load content snapshot for %s@%s: %v", res.TargetName, res.Probe, err)
+					}
+					loaded.LastContent = content
+
+					warnedFor, err := loadCertWarning(ctx, db, res.TargetName, res.Probe)
+					if err != nil {
+						log.Printf("aggregator: load cert warning for %s@%s: %v", res.TargetName, res.Probe, err)
+					}
+					loaded.CertWarnedFor = warnedFor
+				}
+
+				state[key] = loaded
 				st = loaded
 			}
+
+			// Results arrive out of order when an agent replays its buffer or a
+			// slow check finishes after a later one; the newer one already counts.
+			if res.At.Before(st.LastChecked) {
+				continue
+			}
 			
+			// Planned downtime: recorded, but no state changes or events.
+			if res.Maintenance != nil {
+				if db != nil {
+					_ = persistCheckResult(ctx, db, res, "") // best-effort; ignore error for now
+				}
+				updateMaintenanceState(st, res)
+				snapshot.Publish(buildSnapshot(state))
+				continue
+			}
+			st.Maintenance = nil
+
 			prevUp := st.LastUp
-			
+			prevStatus := st.LastStatus
+
 			if st.Name == "Test shop" {
 				fmt.Println(st.LastUp)
 				fmt.Println(res.Up)
 			}
 
 			updateState(st, res)
+			if db != nil {
+				_ = persistCheckResult(ctx, db, res, st.LastStatus) // best-effort; ignore error for now
+			}
 			
-			if prevUp != res.Up {
+			// Only confirmed flips (see confirmState) are transitions.
+			transitioned := prevUp != st.LastUp
+			flapEvent, flapChanged := trackFlapping(st, res, transitioned)
+
+			if transitioned {
 				fmt.Printf("Incident Found for Target: %s", st.Name)
 				event := Event{
+					Kind:       EventTransition,
 					TargetName: res.TargetName,
 					URL:        res.URL,
+					Probe:      res.Probe,
 					From:       prevUp,
 					To:         res.Up,
 					At:         res.At,
-					Reason: res.Error,
+					Reason:     failureReason(res),
 					StatusCode: res.StatusCode,
+					FromStatus: prevStatus,
+					ToStatus:   res.Status(),
+					Latency:    res.Latency,
+					FinalURL:   res.FinalURL,
+					Redirects:  res.Redirects,
+					Flapping:   st.Flapping,
+				}
+
+				// A child going DOWN while a parent is DOWN is caused by the parent;
+				// its recovery is muted the same way as its outage was.
+				if !st.LastUp {
+					st.CausedBy = downParent(state, st.DependsOn, res.Probe)
 				}
+				event.CausedBy = st.CausedBy
+				if st.LastUp {
+					st.CausedBy = ""
+				}
+
 				//push to events
-				select {
-				case eventsCh <- event:
-				case <-ctx.Done():
+				// With a quorum, one probe's flip only feeds the decision below.
+				if res.Alert.Quorum <= 1 {
+					emitEvent(ctx, eventsCh, event)
+				}
 
+				if st.LastUp {
+					for _, ev := range releaseChildren(state, res.TargetName, res.Probe, res.At) {
+						if quorums[ev.TargetName] == nil {
+							emitEvent(ctx, eventsCh, ev)
+						}
+					}
+					for _, ev := range releaseQuorumChildren(state, quorums, res.TargetName, res.At) {
+						emitEvent(ctx, eventsCh, ev)
+					}
+				}
+			}
+
+			var qs *quorumState
+			if res.Alert.Quorum > 1 {
+				qs = quorums[res.TargetName]
+				if qs == nil {
+					loaded, err := loadQuorumState(ctx, db, res.TargetName)
+					if err != nil {
+						log.Printf("aggregator: load quorum state for %s: %v", res.TargetName, err)
+					}
+					quorums[res.TargetName] = loaded
+					qs = loaded
+				}
+
+				if event, ok := evaluateQuorum(state, qs, res, time.Now()); ok {
+					if !event.To {
+						qs.CausedBy = quorumDownParent(state, quorums, res.Alert.DependsOn, event.Probes)
+					}
+					event.CausedBy = qs.CausedBy
+					if event.To {
+						qs.CausedBy = ""
+					}
+					emitEvent(ctx, eventsCh, event)
+
+					if event.To {
+						for _, ev := range releaseQuorumChildren(state, quorums, res.TargetName, res.At) {
+							emitEvent(ctx, eventsCh, ev)
+						}
+					}
+				}
+			}
+
+			if flapChanged && res.Alert.Quorum <= 1 {
+				emitEvent(ctx, eventsCh, flapEvent)
+			}
+
+			// With a quorum, DEGRADED, certificate and content events are
+			// decided once per target instead of once per reporting probe.
+			if qs != nil {
+				if event, ok := evaluateQuorumDegraded(state, qs, res, time.Now()); ok {
+					emitEvent(ctx, eventsCh, event)
+				}
+			} else if event, ok := degradedEvent(prevStatus, st.LastStatus, res); ok {
+				emitEvent(ctx, eventsCh, event)
+			}
+
+			eventProbe, up, certWarnedFor, lastContent := res.Probe, st.LastUp, &st.CertWarnedFor, &st.LastContent
+			if qs != nil {
+				eventProbe, up, certWarnedFor, lastContent = QuorumProbe, !qs.Down, &qs.CertWarnedFor, &qs.LastContent
+			}
+
+			if event, ok := certExpiryEvent(certWarnedFor, eventProbe, up, res); ok {
+				emitEvent(ctx, eventsCh, event)
+				if db != nil {
+					if err := persistCertWarning(ctx, db, res.TargetName, eventProbe, *certWarnedFor, res.At); err != nil {
+						log.Printf("aggregator: persist cert warning for %s@%s: %v", res.TargetName, eventProbe, err)
+					}
+				}
+			}
+
+			if res.Content != nil && (*lastContent == nil || (*lastContent).Hash != res.Content.Hash) {
+				if event, ok := contentChangedEvent(*lastContent, eventProbe, up, res); ok {
+					emitEvent(ctx, eventsCh, event)
+				}
+				*lastContent = res.Content
+				if db != nil {
+					if err := persistContentSnapshot(ctx, db, res.TargetName, eventProbe, res.Content, res.At); err != nil {
+						log.Printf("aggregator: persist content snapshot for %s@%s: %v", res.TargetName, eventProbe, err)
+					}
 				}
 			}
 			//build snapshot
@@ -78,7 +245,48 @@ func Aggregator(ctx context.Context, resCh <-chan CheckResult, eventsCh chan<- E
 	}
 }
 
-func buildSnapshot(states map[string]*State) snapshot.Snapshot {
+// pruneStates drops the state of every target that is not in ts.
+func pruneStates(states map[stateKey]*State, quorums map[string]*quorumState, ts *targetSet) {
+	for k := range states {
+		if _, ok := ts.byName[k.Target]; !ok {
+			delete(states, k)
+		}
+	}
+	for name := range quorums {
+		if _, ok := ts.byName[name]; !ok {
+			delete(quorums, name)
+		}
+	}
+}
+
+// closeRemovedIncidents ends the open incidents of targets that are no longer
+// configured or were disabled, so they don't stay open forever once nothing
+// checks them.
+func closeRemovedIncidents(ctx context.Context, db *pgxpool.Pool, ts *targetSet) {
+	names := make([]string, 0, len(ts.byName))
+	for name := range ts.byName {
+		names = append(names, name)
+	}
+
+	tag, err := db.Exec(ctx, `
+		UPDATE incidents
+		   SET ended_at = now(),
+		       end_status = 'REMOVED',
+		       end_error = 'target removed from config',
+		       updated_at = now()
+		 WHERE ended_at IS NULL
+		   AND NOT (target_name = ANY($1::text[]))
+	`, names)
+	if err != nil {
+		log.Printf("aggregator: close incidents of removed targets: %v", err)
+		return
+	}
+	if n := tag.RowsAffected(); n > 0 {
+		log.Printf("aggregator: closed %d open incidents of targets removed from config", n)
+	}
+}
+
+func buildSnapshot(states map[stateKey]*State) snapshot.Snapshot {
 	all := make([]snapshot.StateDTO, 0, len(states))
 	byName := make(map[string]snapshot.StateDTO, len(states))
 
@@ -86,7 +294,9 @@ func buildSnapshot(states map[string]*State) snapshot.Snapshot {
 		dto := snapshot.StateDTO{
 			Name:        st.Name,
 			URL:         st.URL,
+			Probe:       st.Probe,
 			Up:          st.LastUp,
+			Status:      st.LastStatus,
 			LastChecked: st.LastChecked.UTC().Format(time.RFC3339),
 			LatencyMs:   st.LastLatency.Milliseconds(),
 			StatusCode:  st.LastStatusCode,
@@ -98,8 +308,86 @@ func buildSnapshot(states map[string]*State) snapshot.Snapshot {
 			TotalFails:         st.TotalFails,
 		}
 
+		dto.CausedBy = st.CausedBy
+
+		if m := st.Maintenance; m != nil {
+			dto.Status = StatusMaintenance
+			dto.Maintenance = &snapshot.MaintenanceDTO{
+				Reason: m.Reason,
+				Until:  m.Until.UTC().Format(time.RFC3339),
+			}
+		}
+
+		dto.Flapping = st.Flapping
+		if st.Flapping {
+			dto.FlappingSince = st.FlappingFrom.UTC().Format(time.RFC3339)
+		}
+
+		if st.PendingStatus != "" {
+			dto.Pending = &snapshot.PendingDTO{
+				Status: st.PendingStatus,
+				Count:  st.PendingCount,
+				Needed: st.PendingNeeded,
+			}
+		}
+
+		dto.FinalURL = st.LastFinalURL
+		for _, hop := range st.LastRedirects {
+			dto.Redirects = append(dto.Redirects, snapshot.RedirectHopDTO{
+				StatusCode: hop.StatusCode,
+				URL:        hop.URL,
+				Location:   hop.Location,
+			})
+		}
+
+		for _, step := range st.LastSteps {
+			stepErr := step.Error
+			if stepErr == "" {
+				stepErr = step.Validation
+			}
+			dto.Steps = append(dto.Steps, snapshot.StepDTO{
+				Name:       step.Name,
+				URL:        step.URL,
+				Up:         step.Up,
+				StatusCode: step.StatusCode,
+				LatencyMs:  step.Latency.Milliseconds(),
+				Error:      stepErr,
+			})
+		}
+
+		if t := st.LastTiming; t != nil {
+			dto.Timing = &snapshot.TimingDTO{
+				DNSMs:      t.DNS.Milliseconds(),
+				ConnectMs:  t.Connect.Milliseconds(),
+				TLSMs:      t.TLS.Milliseconds(),
+				TTFBMs:     t.TTFB.Milliseconds(),
+				TransferMs: t.Transfer.Milliseconds(),
+			}
+		}
+
+		dto.Attempts = attemptDTOs(st.LastAttempts)
+
+		if ws := st.LastWebSocket; ws != nil {
+			dto.WebSocket = &snapshot.WebSocketDTO{
+				HandshakeMs:    ws.Handshake.Milliseconds(),
+				FirstMessageMs: ws.FirstMessage.Milliseconds(),
+			}
+		}
+
+		if tlsInfo := st.LastTLS; tlsInfo != nil {
+			days := tlsInfo.DaysToExpiry(time.Now())
+			chainValid := tlsInfo.ChainValid
+			dto.CertNotAfter = tlsInfo.NotAfter.UTC().Format(time.RFC3339)
+			dto.CertDaysToExpiry = &days
+			dto.CertIssuer = tlsInfo.Issuer
+			dto.CertSANs = tlsInfo.SANs
+			dto.CertChainValid = &chainValid
+		}
+
 		all = append(all, dto)
-		byName[dto.Name] = dto
+		if st.Probe == DefaultProbe {
+			byName[dto.Name] = dto
+		}
 	}
 
 	return snapshot.Snapshot{
@@ -109,13 +397,23 @@ func buildSnapshot(states map[string]*State) snapshot.Snapshot {
 }
 
 func updateState(state *State, res CheckResult) {
-	state.LastChecked = time.Now()
-	state.LastUp = res.Up
+	state.LastChecked = resultTime(res)
 	state.Name = res.TargetName
+	state.Probe = res.Probe
 	state.LastLatency = res.Latency
 	state.LastStatusCode = res.StatusCode
 	state.URL = res.URL
 	state.TotalChecks++
+	if res.TLS != nil {
+		state.LastTLS = res.TLS
+	}
+	state.LastFinalURL = res.FinalURL
+	state.LastRedirects = res.Redirects
+	state.LastSteps = res.Steps
+	state.LastTiming = res.Timing
+	state.LastWebSocket = res.WebSocket
+	state.LastAttempts = res.Attempts
+	state.DependsOn = res.Alert.DependsOn
 
 	if res.Up {
 		state.ConsecutiveSuccess++
@@ -123,33 +421,356 @@ func updateState(state *State, res CheckResult) {
 	} else {
 		state.TotalFails++
 		state.ConsecutiveFail++
-		state.LastError = res.Error
+		state.ConsecutiveSuccess = 0
+		state.LastError = failureReason(res)
+	}
+
+	confirmState(state, res)
+}
+
+// downParent returns the first of parents whose confirmed state, as seen from
+// probe, is DOWN, or "".
+func downParent(states map[stateKey]*State, parents []string, probe string) string {
+	for _, p := range parents {
+		if ps := states[stateKey{Target: p, Probe: probe}]; ps != nil && ps.TotalChecks > 0 && !ps.LastUp {
+			return p
+		}
+	}
+	return ""
+}
+
+// releaseChildren is called when parent recovers on probe. Children whose
+// outage was attributed to it but are still DOWN now get their own (notified)
+// DOWN event.
+func releaseChildren(states map[stateKey]*State, parent, probe string, at time.Time) []Event {
+	var events []Event
+	for _, cs := range states {
+		if cs.Probe != probe || cs.CausedBy != parent {
+			continue
+		}
+		cs.CausedBy = ""
+		if cs.LastUp {
+			continue
+		}
+		reason := fmt.Sprintf("still down after %s recovered", parent)
+		if cs.LastError != "" {
+			reason += ": " + cs.LastError
+		}
+		events = append(events, Event{
+			Kind:       EventTransition,
+			TargetName: cs.Name,
+			URL:        cs.URL,
+			Probe:      cs.Probe,
+			From:       true,
+			To:         false,
+			At:         at,
+			Reason:     reason,
+			StatusCode: cs.LastStatusCode,
+			FromStatus: StatusUp,
+			ToStatus:   cs.LastStatus,
+			Flapping:   cs.Flapping,
+		})
+	}
+	return events
+}
+
+// resultTime is when res was checked, which for agent results may be well
+// before it arrives.
+func resultTime(res CheckResult) time.Time {
+	if res.At.IsZero() {
+		return time.Now()
+	}
+	return res.At
+}
+
+// updateMaintenanceState records a check made during a maintenance window
+// without touching the confirmed state, streaks or totals.
+func updateMaintenanceState(state *State, res CheckResult) {
+	state.LastChecked = resultTime(res)
+	state.Name = res.TargetName
+	state.Probe = res.Probe
+	state.URL = res.URL
+	state.LastLatency = res.Latency
+	state.LastStatusCode = res.StatusCode
+	state.Maintenance = res.Maintenance
+	state.PendingStatus, state.PendingCount, state.PendingNeeded = "", 0, 0
+}
+
+// confirmState applies res to the confirmed LastUp/LastStatus once the current
+// streak reaches the target's down_after/up_after threshold. Until then the
+// flip is only recorded as pending, so a single bad check opens no incident.
+func confirmState(state *State, res CheckResult) {
+	// Nothing to confirm against for a target without history.
+	if res.Up == state.LastUp || state.TotalChecks == 1 {
+		state.LastUp = res.Up
+		state.LastStatus = res.Status()
+		state.PendingStatus, state.PendingCount, state.PendingNeeded = "", 0, 0
+		return
+	}
+
+	needed, streak := res.Alert.UpAfter, state.ConsecutiveSuccess
+	if !res.Up {
+		needed, streak = res.Alert.DownAfter, state.ConsecutiveFail
+	}
+	needed = max(needed, 1)
+
+	if streak >= needed {
+		state.LastUp = res.Up
+		state.LastStatus = res.Status()
+		state.PendingStatus, state.PendingCount, state.PendingNeeded = "", 0, 0
+		return
+	}
+
+	state.PendingStatus = StatusUp
+	if !res.Up {
+		state.PendingStatus = StatusDown
+	}
+	state.PendingCount, state.PendingNeeded = streak, needed
+}
+
+// trackFlapping keeps the target's rolling transition log and reports when it
+// starts or stops flapping. Flapping starts once FlapThreshold confirmed
+// transitions fall within FlapWindow, and stops when a whole window passes
+// without any.
+func trackFlapping(state *State, res CheckResult, transitioned bool) (Event, bool) {
+	p := res.Alert
+	now := res.At
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var kept []time.Time
+	if p.FlapThreshold > 0 && p.FlapWindow > 0 {
+		cutoff := now.Add(-p.FlapWindow)
+		kept = state.RecentTransitions[:0]
+		for _, at := range state.RecentTransitions {
+			if at.After(cutoff) {
+				kept = append(kept, at)
+			}
+		}
+		if transitioned {
+			kept = append(kept, now)
+		}
+	}
+	state.RecentTransitions = kept
+
+	var kind, reason string
+	switch {
+	case p.FlapThreshold <= 0 || p.FlapWindow <= 0:
+		// Turned off (e.g. by a config reload): close an ongoing FLAPPING
+		// incident rather than leave it open with alerts silently resumed.
+		if !state.Flapping {
+			return Event{}, false
+		}
+		state.Flapping = false
+		kind = EventFlappingStopped
+		reason = "flap detection turned off"
+	case !state.Flapping && len(kept) >= p.FlapThreshold:
+		state.Flapping = true
+		state.FlappingFrom = kept[0]
+		kind = EventFlappingStarted
+		reason = fmt.Sprintf("%d transitions within %s", len(kept), p.FlapWindow)
+	case state.Flapping && len(kept) == 0:
+		state.Flapping = false
+		kind = EventFlappingStopped
+		reason = fmt.Sprintf("no transitions for %s", p.FlapWindow)
+	default:
+		return Event{}, false
 	}
 
+	return Event{
+		Kind:         kind,
+		TargetName:   res.TargetName,
+		URL:          res.URL,
+		Probe:        res.Probe,
+		From:         state.LastUp,
+		To:           state.LastUp,
+		At:           now,
+		Reason:       reason,
+		StatusCode:   res.StatusCode,
+		ToStatus:     state.LastStatus,
+		FlappingFrom: state.FlappingFrom,
+		Transitions:  len(kept),
+		Flapping:     state.Flapping,
+	}, true
+}
+
+// emitEvent pushes ev to the collector unless we're shutting down.
+func emitEvent(ctx context.Context, eventsCh chan<- Event, ev Event) {
+	select {
+	case eventsCh <- ev:
+	case <-ctx.Done():
+	}
+}
+
+// degradedEvent reports entering or leaving DEGRADED (comparing confirmed
+// statuses). These are tracked as a separate, lower-severity incident kind
+// next to UP/DOWN transitions.
+func degradedEvent(prevStatus, status string, res CheckResult) (Event, bool) {
+	wasDegraded := prevStatus == StatusDegraded
+	isDegraded := status == StatusDegraded
+	if wasDegraded == isDegraded {
+		return Event{}, false
+	}
+
+	kind := EventDegraded
+	if wasDegraded {
+		kind = EventDegradedRecovered
+	}
+
+	return Event{
+		Kind:       kind,
+		TargetName: res.TargetName,
+		URL:        res.URL,
+		Probe:      res.Probe,
+		From:       isUpStatus(prevStatus),
+		To:         res.Up,
+		At:         res.At,
+		Reason:     failureReason(res),
+		StatusCode: res.StatusCode,
+		FromStatus: prevStatus,
+		ToStatus:   status,
+		Latency:    res.Latency,
+	}, true
+}
+
+// failureReason prefers the transport error and falls back to the validation message.
+func failureReason(res CheckResult) string {
+	if res.Error != "" {
+		return res.Error
+	}
+	return res.Validation
 }
 
-// loadStateFromDB tries to reconstruct the last known state for a target from check_results.
-func loadStateFromDB(ctx context.Context, db *pgxpool.Pool, target string) (*State, error) {
+// certExpiryEvent returns a warning event the first time a given certificate
+// enters its expiry window. A renewed certificate (new NotAfter) re-arms it.
+// warnedFor is the State's (or, with a quorum, the quorumState's) last warning.
+func certExpiryEvent(warnedFor *time.Time, probe string, up bool, res CheckResult) (Event, bool) {
+	if res.TLS == nil || !res.TLS.Expiring || warnedFor.Equal(res.TLS.NotAfter) {
+		return Event{}, false
+	}
+	*warnedFor = res.TLS.NotAfter
+
+	return Event{
+		Kind:       EventCertExpiring,
+		TargetName: res.TargetName,
+		URL:        res.URL,
+		Probe:      probe,
+		From:       up,
+		To:         up,
+		At:         res.At,
+		Reason:     fmt.Sprintf("certificate expires in %d days", res.TLS.DaysToExpiry(res.At)),
+		StatusCode: res.StatusCode,
+		TLS:        res.TLS,
+	}, true
+}
+
+// contentChangedEvent reports a change of watched content against last. The
+// first snapshot of a target is only a baseline and raises nothing.
+func contentChangedEvent(last *ContentSnapshot, probe string, up bool, res CheckResult) (Event, bool) {
+	if last == nil {
+		return Event{}, false
+	}
+
+	reason := "page content changed"
+	if res.Content.Truncated {
+		reason += " (body truncated at max_body_bytes; only the start of the page is compared)"
+	}
+
+	return Event{
+		Kind:       EventContentChanged,
+		TargetName: res.TargetName,
+		URL:        res.URL,
+		Probe:      probe,
+		From:       up,
+		To:         up,
+		At:         res.At,
+		Reason:     reason,
+		StatusCode: res.StatusCode,
+		Diff:       contentDiff(last.Text, res.Content.Text),
+	}, true
+}
+
+// loadContentSnapshot returns the last stored content for target on probe, or nil if none.
+func loadContentSnapshot(ctx context.Context, db *pgxpool.Pool, target, probe string) (*ContentSnapshot, error) {
+	var c ContentSnapshot
+	err := db.QueryRow(ctx,
+		`SELECT hash, content FROM content_snapshots WHERE target_name = $1 AND probe = $2`,
+		target, probe,
+	).Scan(&c.Hash, &c.Text)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+// persistContentSnapshot stores the latest watched content so changes are
+// still detected across restarts.
+func persistContentSnapshot(ctx context.Context, db *pgxpool.Pool, target, probe string, c *ContentSnapshot, at time.Time) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO content_snapshots (target_name, probe, hash, content, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (target_name, probe) DO UPDATE
+		   SET hash = EXCLUDED.hash, content = EXCLUDED.content, updated_at = EXCLUDED.updated_at
+	`, target, probe, c.Hash, c.Text, at)
+	return err
+}
+
+// loadCertWarning returns the NotAfter of the certificate last warned about
+// for target on probe, or the zero time if none.
+func loadCertWarning(ctx context.Context, db *pgxpool.Pool, target, probe string) (time.Time, error) {
+	var notAfter time.Time
+	err := db.QueryRow(ctx,
+		`SELECT not_after FROM cert_warnings WHERE target_name = $1 AND probe = $2`,
+		target, probe,
+	).Scan(&notAfter)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, err
+	}
+	return notAfter, nil
+}
+
+// persistCertWarning records that the certificate expiring at notAfter was
+// warned about, so restarts and config reloads don't warn about it again.
+func persistCertWarning(ctx context.Context, db *pgxpool.Pool, target, probe string, notAfter, at time.Time) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO cert_warnings (target_name, probe, not_after, warned_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (target_name, probe) DO UPDATE
+		   SET not_after = EXCLUDED.not_after, warned_at = EXCLUDED.warned_at
+	`, target, probe, notAfter, at)
+	return err
+}
+
+// loadStateFromDB tries to reconstruct the last known state for a target on probe from check_results.
+// The confirmed status comes from confirmed_status, so a restart in the middle
+// of a down_after/up_after streak resumes it instead of taking the raw status
+// of the last check as confirmed (see restoreStreak).
+func loadStateFromDB(ctx context.Context, db *pgxpool.Pool, target, probe string, p AlertPolicy) (*State, error) {
 	if db == nil {
 		return nil, errors.New("db pool nil")
 	}
 
 	var (
 		checkedAt  time.Time
-		status     string
+		confirmed  string
 		statusCode int
 		latencyMs  int64
 		errText    *string
+		attempts   []byte
 	)
 
 	err := db.QueryRow(ctx, `
-		SELECT checked_at, status, COALESCE(status_code, 0), COALESCE(latency_ms, 0), error
+		SELECT checked_at, COALESCE(confirmed_status, status), COALESCE(status_code, 0), COALESCE(latency_ms, 0), error, attempts
 		  FROM check_results
-		 WHERE target_name = $1
+		 WHERE target_name = $1 AND probe = $2 AND status <> 'MAINTENANCE'
 		 ORDER BY checked_at DESC
 		 LIMIT 1`,
-		target,
-	).Scan(&checkedAt, &status, &statusCode, &latencyMs, &errText)
+		target, probe,
+	).Scan(&checkedAt, &confirmed, &statusCode, &latencyMs, &errText, &attempts)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -160,8 +781,10 @@ func loadStateFromDB(ctx context.Context, db *pgxpool.Pool, target string) (*Sta
 
 	st := &State{
 		Name:           target,
+		Probe:          probe,
 		LastChecked:    checkedAt,
-		LastUp:         strings.EqualFold(status, "UP"),
+		LastUp:         isUpStatus(confirmed),
+		LastStatus:     normalizeStatus(confirmed),
 		LastLatency:    time.Duration(latencyMs) * time.Millisecond,
 		LastStatusCode: statusCode,
 		LastError:      "",
@@ -169,14 +792,20 @@ func loadStateFromDB(ctx context.Context, db *pgxpool.Pool, target string) (*Sta
 	if errText != nil {
 		st.LastError = *errText
 	}
+	if len(attempts) > 0 {
+		var dtos []snapshot.AttemptDTO
+		if err := json.Unmarshal(attempts, &dtos); err == nil {
+			st.LastAttempts = attemptRecords(dtos)
+		}
+	}
 
 	// Totals
 	var total, fails int64
 	if err := db.QueryRow(ctx,
-		`SELECT COUNT(*), COUNT(*) FILTER (WHERE status <> 'UP')
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE status NOT IN ('UP', 'DEGRADED'))
 		   FROM check_results
-		  WHERE target_name = $1`,
-		target,
+		  WHERE target_name = $1 AND probe = $2 AND status <> 'MAINTENANCE'`,
+		target, probe,
 	).Scan(&total, &fails); err == nil {
 		st.TotalChecks = int(total)
 		st.TotalFails = int(fails)
@@ -186,64 +815,137 @@ func loadStateFromDB(ctx context.Context, db *pgxpool.Pool, target string) (*Sta
 	rows, err := db.Query(ctx,
 		`SELECT status
 		   FROM check_results
-		  WHERE target_name = $1
+		  WHERE target_name = $1 AND probe = $2 AND status <> 'MAINTENANCE'
 		  ORDER BY checked_at DESC
 		  LIMIT 100`,
-		target,
+		target, probe,
 	)
 	if err == nil {
 		defer rows.Close()
-		streak := 0
-		var firstStatus string
+		var recent []string
 		for rows.Next() {
 			var s string
 			if scanErr := rows.Scan(&s); scanErr != nil {
 				break
 			}
-			if streak == 0 {
-				firstStatus = s
-			}
-			if !strings.EqualFold(s, firstStatus) {
-				break
-			}
-			streak++
-		}
-		if st.LastUp {
-			st.ConsecutiveSuccess = streak
-			st.ConsecutiveFail = 0
-		} else {
-			st.ConsecutiveFail = streak
-			st.ConsecutiveSuccess = 0
+			recent = append(recent, s)
 		}
+		restoreStreak(st, recent, p)
 	}
 
 	return st, nil
 }
 
-func persistCheckResult(ctx context.Context, db *pgxpool.Pool, res CheckResult) error {
-	status := "DOWN"
+// restoreStreak sets the success/failure streak from recent raw statuses
+// (newest first). When the streak disagrees with the confirmed status it is a
+// flip still waiting for down_after/up_after, restored as pending.
+func restoreStreak(st *State, recent []string, p AlertPolicy) {
+	streak := 0
+	for _, s := range recent {
+		// DEGRADED counts as success for streak purposes.
+		if isUpStatus(s) != isUpStatus(recent[0]) {
+			break
+		}
+		streak++
+	}
+	if streak == 0 {
+		return
+	}
+
+	rawUp := isUpStatus(recent[0])
+	if rawUp {
+		st.ConsecutiveSuccess, st.ConsecutiveFail = streak, 0
+	} else {
+		st.ConsecutiveFail, st.ConsecutiveSuccess = streak, 0
+	}
+	if rawUp == st.LastUp {
+		return
+	}
+
+	st.PendingStatus, st.PendingNeeded = StatusUp, max(p.UpAfter, 1)
+	if !rawUp {
+		st.PendingStatus, st.PendingNeeded = StatusDown, max(p.DownAfter, 1)
+	}
+	st.PendingCount = streak
+}
+
+// persistCheckResult stores res with the target's confirmed status after it
+// (see confirmState); confirmed is empty for checks that don't change state.
+func persistCheckResult(ctx context.Context, db *pgxpool.Pool, res CheckResult, confirmed string) error {
+	status := StatusDown
 	switch {
+	case res.Maintenance != nil:
+		status = StatusMaintenance
 	case res.Up:
-		status = "UP"
+		status = res.Status()
 	case errorsIsContextDeadline(errors.New(res.Error)) || strings.Contains(strings.ToLower(res.Error), "timeout"):
-		status = "TIMEOUT"
+		status = StatusTimeout
+	}
+
+	checkedAt := resultTime(res)
+
+	// Phase columns stay NULL for checks without a breakdown (tcp, dns, ...).
+	var dnsMs, connectMs, tlsMs, ttfbMs, transferMs any
+	if t := res.Timing; t != nil {
+		dnsMs, connectMs, tlsMs = t.DNS.Milliseconds(), t.Connect.Milliseconds(), t.TLS.Milliseconds()
+		ttfbMs, transferMs = t.TTFB.Milliseconds(), t.Transfer.Milliseconds()
 	}
 
-	checkedAt := res.At
-	if checkedAt.IsZero() {
-		checkedAt = time.Now()
+	// Every attempt of a retried check, in the same shape as the API's attempts.
+	var attempts any
+	if len(res.Attempts) > 0 {
+		b, err := json.Marshal(attemptDTOs(res.Attempts))
+		if err != nil {
+			return err
+		}
+		attempts = string(b)
 	}
 
 	_, err := db.Exec(ctx, `
 		INSERT INTO check_results
-			(target_name, checked_at, status, status_code, latency_ms, error, probe)
+			(target_name, checked_at, status, status_code, latency_ms, error, probe,
+			 dns_ms, connect_ms, tls_ms, ttfb_ms, transfer_ms, attempt, attempts, confirmed_status)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7)
-	`, res.TargetName, checkedAt, status, res.StatusCode, res.Latency.Milliseconds(), nullableString(res.Error, res.Validation), "primary")
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14::jsonb, $15)
+	`, res.TargetName, checkedAt, status, res.StatusCode, res.Latency.Milliseconds(), nullableString(res.Error, res.Validation), res.Probe,
+		dnsMs, connectMs, tlsMs, ttfbMs, transferMs, max(res.Attempt, 1), attempts, nullableString(confirmed))
 
 	return err
 }
 
+// attemptDTOs converts attempt records to their API / check_results.attempts form.
+func attemptDTOs(attempts []AttemptRecord) []snapshot.AttemptDTO {
+	var out []snapshot.AttemptDTO
+	for _, a := range attempts {
+		out = append(out, snapshot.AttemptDTO{
+			Attempt:    a.Attempt,
+			At:         a.At.UTC().Format(time.RFC3339),
+			LatencyMs:  a.Latency.Milliseconds(),
+			Up:         a.Up,
+			StatusCode: a.StatusCode,
+			Error:      a.Reason,
+		})
+	}
+	return out
+}
+
+// attemptRecords is the inverse of attemptDTOs, for state loaded from check_results.
+func attemptRecords(dtos []snapshot.AttemptDTO) []AttemptRecord {
+	var out []AttemptRecord
+	for _, d := range dtos {
+		at, _ := time.Parse(time.RFC3339, d.At)
+		out = append(out, AttemptRecord{
+			Attempt:    d.Attempt,
+			At:         at,
+			Latency:    time.Duration(d.LatencyMs) * time.Millisecond,
+			Up:         d.Up,
+			StatusCode: d.StatusCode,
+			Reason:     d.Error,
+		})
+	}
+	return out
+}
+
 func nullableString(parts ...string) any {
 	for _, p := range parts {
 		if strings.TrimSpace(p) != "" {
@@ -252,3 +954,20 @@ func nullableString(parts ...string) any {
 	}
 	return nil
 }
+
+// isUpStatus reports whether a check_results.status counts as reachable.
+func isUpStatus(status string) bool {
+	return strings.EqualFold(status, StatusUp) || strings.EqualFold(status, StatusDegraded)
+}
+
+// normalizeStatus maps stored statuses onto the UP/DEGRADED/DOWN tri-state.
+func normalizeStatus(status string) string {
+	switch strings.ToUpper(status) {
+	case StatusUp:
+		return StatusUp
+	case StatusDegraded:
+		return StatusDegraded
+	default:
+		return StatusDown
+	}
+}
@@ -0,0 +1,257 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// checkSeq turns "UUDG" into results: U = up, D = down, G = up but DEGRADED.
+func checkSeq(seq string, p AlertPolicy) []CheckResult {
+	out := make([]CheckResult, 0, len(seq))
+	for _, c := range seq {
+		out = append(out, CheckResult{
+			TargetName: "t",
+			Up:         c != 'D',
+			Degraded:   c == 'G',
+			Alert:      p,
+		})
+	}
+	return out
+}
+
+func TestConfirmState(t *testing.T) {
+	tests := []struct {
+		name      string
+		downAfter int
+		upAfter   int
+		checks    string
+		want      string // confirmed status after each check: U, D or G
+	}{
+		{name: "defaults flip at once", checks: "UDUD", want: "UDUD"},
+		{name: "first check is taken as is", downAfter: 3, checks: "DDU", want: "DDU"},
+		{name: "down after 3", downAfter: 3, checks: "UDDDU", want: "UUUDU"},
+		{name: "failure streak broken", downAfter: 3, checks: "UDDUDD", want: "UUUUUU"},
+		{name: "up after 2", upAfter: 2, checks: "DUUD", want: "DDUD"},
+		{name: "both thresholds", downAfter: 2, upAfter: 2, checks: "UDUDDUDUU", want: "UUUUDDDDU"},
+		{name: "degraded is up", downAfter: 2, checks: "UGGDU", want: "UGGGU"},
+	}
+
+	for _, tt := range tests {
+		p := AlertPolicy{DownAfter: tt.downAfter, UpAfter: tt.upAfter}
+		st := &State{}
+		var got []byte
+		for _, res := range checkSeq(tt.checks, p) {
+			updateState(st, res)
+			switch st.LastStatus {
+			case StatusUp:
+				got = append(got, 'U')
+			case StatusDegraded:
+				got = append(got, 'G')
+			default:
+				got = append(got, 'D')
+			}
+			if st.LastUp != (st.LastStatus != StatusDown) {
+				t.Fatalf("%s: LastUp %v disagrees with LastStatus %s", tt.name, st.LastUp, st.LastStatus)
+			}
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: checks %s confirmed %s, want %s", tt.name, tt.checks, got, tt.want)
+		}
+	}
+}
+
+func TestConfirmStatePending(t *testing.T) {
+	p := AlertPolicy{DownAfter: 3}
+	st := &State{}
+	for _, res := range checkSeq("UDD", p) {
+		updateState(st, res)
+	}
+	if st.PendingStatus != StatusDown || st.PendingCount != 2 || st.PendingNeeded != 3 {
+		t.Errorf("pending = %s %d/%d, want DOWN 2/3", st.PendingStatus, st.PendingCount, st.PendingNeeded)
+	}
+
+	updateState(st, checkSeq("U", p)[0])
+	if st.PendingStatus != "" || st.PendingCount != 0 {
+		t.Errorf("pending not cleared by a success: %s %d", st.PendingStatus, st.PendingCount)
+	}
+}
+
+func TestRestoreStreak(t *testing.T) {
+	tests := []struct {
+		name        string
+		confirmedUp bool
+		recent      []string // newest first
+		policy      AlertPolicy
+		wantFail    int
+		wantSuccess int
+		wantPending string
+		wantCount   int
+	}{
+		{name: "settled up", confirmedUp: true, recent: []string{"UP", "DEGRADED", "DOWN"}, wantSuccess: 2},
+		{name: "settled down", recent: []string{"TIMEOUT", "DOWN", "UP"}, wantFail: 2},
+		{name: "failing, not yet down", confirmedUp: true, recent: []string{"DOWN", "TIMEOUT", "UP"}, policy: AlertPolicy{DownAfter: 3},
+			wantFail: 2, wantPending: StatusDown, wantCount: 2},
+		{name: "recovering, not yet up", recent: []string{"UP", "DOWN"}, policy: AlertPolicy{UpAfter: 2},
+			wantSuccess: 1, wantPending: StatusUp, wantCount: 1},
+		{name: "no history", confirmedUp: true},
+	}
+
+	for _, tt := range tests {
+		st := &State{LastUp: tt.confirmedUp}
+		restoreStreak(st, tt.recent, tt.policy)
+		if st.ConsecutiveFail != tt.wantFail || st.ConsecutiveSuccess != tt.wantSuccess {
+			t.Errorf("%s: streak fail %d success %d, want %d %d", tt.name, st.ConsecutiveFail, st.ConsecutiveSuccess, tt.wantFail, tt.wantSuccess)
+		}
+		if st.PendingStatus != tt.wantPending || st.PendingCount != tt.wantCount {
+			t.Errorf("%s: pending %q %d, want %q %d", tt.name, st.PendingStatus, st.PendingCount, tt.wantPending, tt.wantCount)
+		}
+	}
+}
+
+// TestHydrateMidStreak restarts after 2 of 3 failures: the third confirms
+// DOWN as a transition, as it would have without the restart.
+func TestHydrateMidStreak(t *testing.T) {
+	p := AlertPolicy{DownAfter: 3}
+	st := &State{LastUp: true, LastStatus: StatusUp, TotalChecks: 10}
+	restoreStreak(st, []string{"DOWN", "DOWN", "UP", "UP"}, p)
+	if st.PendingNeeded != 3 {
+		t.Errorf("pending needed = %d, want 3", st.PendingNeeded)
+	}
+
+	updateState(st, checkSeq("D", p)[0])
+	if st.LastUp || st.LastStatus != StatusDown {
+		t.Errorf("after the third failure: confirmed %s, want DOWN", st.LastStatus)
+	}
+
+	// And in reverse: confirmed DOWN, one success in, up_after 2.
+	p = AlertPolicy{UpAfter: 2}
+	st = &State{LastStatus: StatusDown, TotalChecks: 10}
+	restoreStreak(st, []string{"UP", "DOWN"}, p)
+	updateState(st, checkSeq("U", p)[0])
+	if !st.LastUp {
+		t.Error("after the second success: still DOWN, want UP")
+	}
+}
+
+// TestAggregatorDropsOutOfOrder replays a result older than the last one seen:
+// it must neither flip the state back nor count as a fresh check.
+func TestAggregatorDropsOutOfOrder(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	resCh := make(chan CheckResult, 3)
+	eventsCh := make(chan Event, 10)
+	resCh <- CheckResult{TargetName: "t", At: base, Up: true}
+	resCh <- CheckResult{TargetName: "t", At: base.Add(2 * time.Minute)}
+	resCh <- CheckResult{TargetName: "t", At: base.Add(time.Minute), Up: true}
+	close(resCh)
+
+	Aggregator(context.Background(), resCh, eventsCh, nil)
+	close(eventsCh)
+
+	var last Event
+	for ev := range eventsCh {
+		last = ev
+	}
+	if last.To || !last.At.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("last event to %v at %s, want DOWN at %s", last.To, last.At, base.Add(2*time.Minute))
+	}
+}
+
+func TestUpdateStateUsesCheckTime(t *testing.T) {
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	st := &State{}
+	updateState(st, CheckResult{TargetName: "t", At: at, Up: true})
+	if !st.LastChecked.Equal(at) {
+		t.Errorf("LastChecked = %s, want the result's %s", st.LastChecked, at)
+	}
+}
+
+func TestTrackFlapping(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := AlertPolicy{FlapThreshold: 3, FlapWindow: 10 * time.Minute}
+
+	type step struct {
+		after        time.Duration // since base
+		transitioned bool
+		want         string // event kind, "" for none
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "below threshold", steps: []step{
+			{0, true, ""},
+			{time.Minute, true, ""},
+			{2 * time.Minute, false, ""},
+		}},
+		{name: "starts at threshold", steps: []step{
+			{0, true, ""},
+			{time.Minute, true, ""},
+			{2 * time.Minute, true, EventFlappingStarted},
+			{3 * time.Minute, true, ""},
+		}},
+		{name: "transitions spread beyond the window", steps: []step{
+			{0, true, ""},
+			{6 * time.Minute, true, ""},
+			{12 * time.Minute, true, ""},
+		}},
+		{name: "stops after a quiet window", steps: []step{
+			{0, true, ""},
+			{time.Minute, true, ""},
+			{2 * time.Minute, true, EventFlappingStarted},
+			{11 * time.Minute, false, ""},
+			{12*time.Minute + time.Second, false, EventFlappingStopped},
+			{13 * time.Minute, false, ""},
+		}},
+	}
+
+	for _, tt := range tests {
+		st := &State{}
+		for i, s := range tt.steps {
+			res := CheckResult{TargetName: "t", At: base.Add(s.after), Alert: p}
+			ev, ok := trackFlapping(st, res, s.transitioned)
+			got := ""
+			if ok {
+				got = ev.Kind
+			}
+			if got != s.want {
+				t.Errorf("%s: step %d event %q, want %q", tt.name, i, got, s.want)
+			}
+		}
+	}
+}
+
+func TestTrackFlappingStartedEvent(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := AlertPolicy{FlapThreshold: 2, FlapWindow: time.Hour}
+	st := &State{}
+
+	trackFlapping(st, CheckResult{At: base, Alert: p}, true)
+	ev, ok := trackFlapping(st, CheckResult{At: base.Add(time.Minute), Alert: p}, true)
+	if !ok || !st.Flapping {
+		t.Fatal("want flapping to start")
+	}
+	if !ev.FlappingFrom.Equal(base) || ev.Transitions != 2 || !ev.Flapping {
+		t.Errorf("event = from %s, %d transitions, flapping %v; want from %s, 2, true", ev.FlappingFrom, ev.Transitions, ev.Flapping, base)
+	}
+}
+
+func TestTrackFlappingDisabled(t *testing.T) {
+	st := &State{RecentTransitions: []time.Time{time.Now()}}
+	if _, ok := trackFlapping(st, CheckResult{At: time.Now()}, true); ok {
+		t.Error("want no event without a flap threshold")
+	}
+	if st.RecentTransitions != nil {
+		t.Error("want transitions cleared without a flap threshold")
+	}
+
+	// Threshold removed while flapping: the FLAPPING incident must be closed.
+	st = &State{Flapping: true, RecentTransitions: []time.Time{time.Now()}}
+	ev, ok := trackFlapping(st, CheckResult{At: time.Now()}, true)
+	if !ok || ev.Kind != EventFlappingStopped {
+		t.Errorf("event %q (%v), want %q", ev.Kind, ok, EventFlappingStopped)
+	}
+	if st.Flapping || st.RecentTransitions != nil {
+		t.Error("want flapping state cleared without a flap threshold")
+	}
+}
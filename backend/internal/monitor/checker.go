@@ -0,0 +1,108 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target types understood by the default registry; config accepts these as
+// a target's type.
+const (
+	TypeHTTP      = "http"
+	TypeTCP       = "tcp"
+	TypeDNS       = "dns"
+	TypeTLS       = "tls"
+	TypeSteps     = "steps"
+	TypeGRPC      = "grpc"
+	TypeWebSocket = "websocket"
+)
+
+// Checker executes a single probe of one kind (http, tcp, ...) against a target.
+// Implementations must honour ctx for cancellation/timeout and always return a
+// CheckResult, encoding failures in Up/Error/Validation instead of panicking.
+type Checker interface {
+	Check(ctx context.Context, t Target) CheckResult
+}
+
+// Registry maps a target type to the Checker that handles it.
+// It is safe for concurrent use so workers can look up checkers while
+// new kinds are registered.
+type Registry struct {
+	mu       sync.RWMutex
+	checkers map[string]Checker
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{checkers: make(map[string]Checker)}
+}
+
+// NewDefaultRegistry returns a registry with every built-in checker registered.
+// client is shared by all HTTP-based checkers.
+func NewDefaultRegistry(client *http.Client) *Registry {
+	r := NewRegistry()
+	r.Register(TypeHTTP, NewHTTPChecker(client))
+	r.Register(TypeTCP, NewTCPChecker())
+	r.Register(TypeDNS, NewDNSChecker())
+	r.Register(TypeTLS, NewTLSChecker())
+	r.Register(TypeSteps, NewStepsChecker(client))
+	r.Register(TypeGRPC, NewGRPCChecker())
+	r.Register(TypeWebSocket, NewWebSocketChecker())
+	return r
+}
+
+// Register adds (or replaces) the checker for the given type.
+func (r *Registry) Register(kind string, c Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers[normalizeType(kind)] = c
+}
+
+// Lookup returns the checker for the given type. An empty type means http.
+func (r *Registry) Lookup(kind string) (Checker, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.checkers[normalizeType(kind)]
+	return c, ok
+}
+
+// Check dispatches to the checker registered for t.Type.
+// Unknown types produce a DOWN result rather than an error so they still
+// surface in /status and check_results.
+func (r *Registry) Check(ctx context.Context, t Target) CheckResult {
+	c, ok := r.Lookup(t.Type)
+	if !ok {
+		return CheckResult{
+			TargetName: t.Name,
+			URL:        t.URL,
+			At:         time.Now(),
+			Up:         false,
+			Error:      fmt.Sprintf("no checker registered for type %q", t.Type),
+			Attempt:    1,
+		}
+	}
+	return applyDegradedLatency(c.Check(ctx, t), t)
+}
+
+// applyDegradedLatency marks a passing result as DEGRADED when it was slower
+// than the target's threshold. Failing results are left untouched.
+func applyDegradedLatency(res CheckResult, t Target) CheckResult {
+	if !res.Up || t.DegradedLatency <= 0 || res.Latency < t.DegradedLatency {
+		return res
+	}
+	res.Degraded = true
+	res.Validation = fmt.Sprintf("slow response: %s >= %s", res.Latency.Round(time.Millisecond), t.DegradedLatency)
+	return res
+}
+
+func normalizeType(kind string) string {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" {
+		return TypeHTTP
+	}
+	return kind
+}
@@ -0,0 +1,170 @@
+package monitor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// ContentWatch enables change detection on a GET target. The body (or the part
+// selected by Selector or Region) is reduced to normalised text and hashed; a
+// different hash from the previous check raises a CONTENT_CHANGED event.
+type ContentWatch struct {
+	Selector       cascadia.Selector // optional: only text inside matching elements
+	SelectorSource string            // Selector as written in the config
+	Region         *regexp.Regexp    // optional: only the first match (capture group 1 when present)
+}
+
+// ContentSnapshot is the normalised content seen by one check.
+type ContentSnapshot struct {
+	Hash string // hex sha256 of Text
+	Text string
+
+	// Body was cut off at max_body_bytes: changes past the limit are not seen,
+	// and a shifting cut-off point can change Hash on its own.
+	Truncated bool
+}
+
+// maxDiffLines caps the lines reported in a CONTENT_CHANGED event.
+const maxDiffLines = 10
+
+// snapshot reduces body to normalised text and hashes it. HTML is reduced to
+// its visible text so per-request noise in markup (nonces, CSRF tokens in
+// attributes) doesn't count as a change.
+func (w *ContentWatch) snapshot(body []byte, contentType string) *ContentSnapshot {
+	isHTML := strings.Contains(strings.ToLower(contentType), "html")
+
+	var text string
+	switch {
+	case w.Selector != nil:
+		doc, err := html.Parse(bytes.NewReader(body))
+		if err != nil {
+			return nil
+		}
+		var parts []string
+		for _, n := range w.Selector.MatchAll(doc) {
+			parts = append(parts, htmlText(n))
+		}
+		text = strings.Join(parts, "\n")
+	case w.Region != nil:
+		m := w.Region.FindSubmatch(body)
+		if m == nil {
+			text = ""
+			break
+		}
+		region := m[0]
+		if len(m) > 1 {
+			region = m[1]
+		}
+		text = string(region)
+		if isHTML {
+			text = htmlFragmentText(text)
+		}
+	case isHTML:
+		text = htmlFragmentText(string(body))
+	default:
+		text = string(body)
+	}
+
+	text = normalizeContent(text)
+	sum := sha256.Sum256([]byte(text))
+	return &ContentSnapshot{Hash: hex.EncodeToString(sum[:]), Text: text}
+}
+
+// htmlFragmentText parses s as HTML and returns its visible text.
+func htmlFragmentText(s string) string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return s
+	}
+	return htmlText(doc)
+}
+
+// htmlText returns the visible text under n, one line per block element.
+func htmlText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript", "template":
+				return
+			case "a", "span", "b", "i", "em", "strong", "small", "code", "abbr", "sub", "sup":
+			default:
+				b.WriteByte('\n')
+				defer b.WriteByte('\n')
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// normalizeContent collapses whitespace within lines and drops blank lines.
+func normalizeContent(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, l := range lines {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			out = append(out, l)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// contentDiff returns a short line diff: lines only in before prefixed "- ",
+// lines only in after prefixed "+ ", at most maxDiffLines in total.
+func contentDiff(before, after string) string {
+	oldLines, newLines := strings.Split(before, "\n"), strings.Split(after, "\n")
+	inOld := make(map[string]int, len(oldLines))
+	for _, l := range oldLines {
+		inOld[l]++
+	}
+	inNew := make(map[string]int, len(newLines))
+	for _, l := range newLines {
+		inNew[l]++
+	}
+
+	var diff []string
+	for _, l := range oldLines {
+		if inNew[l] > 0 {
+			inNew[l]--
+			continue
+		}
+		diff = append(diff, "- "+truncateLine(l))
+	}
+	for _, l := range newLines {
+		if inOld[l] > 0 {
+			inOld[l]--
+			continue
+		}
+		diff = append(diff, "+ "+truncateLine(l))
+	}
+
+	if len(diff) > maxDiffLines {
+		more := len(diff) - maxDiffLines
+		diff = append(diff[:maxDiffLines], fmt.Sprintf("… %d more changed lines", more))
+	}
+	return strings.Join(diff, "\n")
+}
+
+func truncateLine(s string) string {
+	const max = 120
+	if r := []rune(s); len(r) > max {
+		return string(r[:max]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/cascadia"
+)
+
+func TestContentDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          string
+	}{
+		{name: "same", before: "a\nb", after: "a\nb", want: ""},
+		{name: "changed line", before: "a\nb\nc", after: "a\nB\nc", want: "- b\n+ B"},
+		{name: "added", before: "a", after: "a\nb", want: "+ b"},
+		{name: "removed", before: "a\nb", after: "b", want: "- a"},
+		{name: "reordered only", before: "a\nb", after: "b\na", want: ""},
+		{name: "duplicate removed", before: "a\na\nb", after: "a\nb", want: "- a"},
+		{name: "duplicate added", before: "a", after: "a\na", want: "+ a"},
+	}
+
+	for _, tt := range tests {
+		if got := contentDiff(tt.before, tt.after); got != tt.want {
+			t.Errorf("%s: contentDiff = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestContentDiffCapsLines(t *testing.T) {
+	var after []string
+	for i := 0; i < maxDiffLines+5; i++ {
+		after = append(after, strings.Repeat("x", i+1))
+	}
+
+	lines := strings.Split(contentDiff("", strings.Join(after, "\n")), "\n")
+	if len(lines) != maxDiffLines+1 {
+		t.Fatalf("got %d lines, want %d plus a summary", len(lines), maxDiffLines)
+	}
+	// "" is the only old line, so 1 removal + 15 additions = 16 changes.
+	if want := "… 6 more changed lines"; lines[maxDiffLines] != want {
+		t.Errorf("summary = %q, want %q", lines[maxDiffLines], want)
+	}
+}
+
+func TestContentDiffTruncatesLongLines(t *testing.T) {
+	got := contentDiff("", strings.Repeat("é", 200))
+	if want := "+ " + strings.Repeat("é", 120) + "…"; !strings.HasSuffix(got, want) {
+		t.Errorf("long line not truncated to 120 runes: %q", got)
+	}
+}
+
+func TestContentWatchSnapshot(t *testing.T) {
+	page := []byte(`<html><body>
+		<div id="main"><h1>Hello</h1>  <p>World   again</p></div>
+		<script>var nonce = "abc";</script>
+		<footer>Generated 12:00</footer>
+	</body></html>`)
+
+	tests := []struct {
+		name  string
+		watch ContentWatch
+		ctype string
+		want  string
+	}{
+		{name: "html text", ctype: "text/html", want: "Hello\nWorld again\nGenerated 12:00"},
+		{name: "selector", watch: ContentWatch{Selector: cascadia.MustCompile("#main")}, ctype: "text/html", want: "Hello\nWorld again"},
+		{name: "region", watch: ContentWatch{Region: regexp.MustCompile(`(?s)<h1>(.*?)</h1>`)}, ctype: "text/html", want: "Hello"},
+		{name: "region without match", watch: ContentWatch{Region: regexp.MustCompile(`<nav>`)}, ctype: "text/html", want: ""},
+	}
+
+	for _, tt := range tests {
+		snap := tt.watch.snapshot(page, tt.ctype)
+		if snap == nil {
+			t.Fatalf("%s: nil snapshot", tt.name)
+		}
+		if snap.Text != tt.want {
+			t.Errorf("%s: text = %q, want %q", tt.name, snap.Text, tt.want)
+		}
+	}
+
+	// Markup-only differences don't change the hash.
+	a := (&ContentWatch{}).snapshot([]byte(`<p class="x">Hi</p>`), "text/html")
+	b := (&ContentWatch{}).snapshot([]byte(`<p class="y" data-csrf="123">Hi</p>`), "text/html")
+	if a.Hash != b.Hash {
+		t.Error("attribute-only change altered the hash")
+	}
+}
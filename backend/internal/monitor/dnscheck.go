@@ -0,0 +1,189 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DNSChecker is the Checker for "dns" targets (url: dns://hostname).
+// It resolves one record type and asserts the expected values / record count.
+type DNSChecker struct{}
+
+// NewDNSChecker returns a DNS checker. Resolvers are built per target because
+// each target may point at a different nameserver.
+func NewDNSChecker() *DNSChecker {
+	return &DNSChecker{}
+}
+
+func (c *DNSChecker) Check(ctx context.Context, t Target) CheckResult {
+	start := time.Now()
+
+	res := CheckResult{
+		TargetName: t.Name,
+		URL:        t.URL,
+		At:         time.Now(),
+		Attempt:    1,
+	}
+
+	host, err := dnsHost(t.URL)
+	if err != nil {
+		res.Error = err.Error()
+		res.Latency = time.Since(start)
+		return res
+	}
+
+	recordType := strings.ToUpper(t.DNS.RecordType)
+	if recordType == "" {
+		recordType = "A"
+	}
+
+	records, err := lookupRecords(ctx, newResolver(t.DNS.Resolver), recordType, host)
+	res.Latency = time.Since(start)
+	if err != nil {
+		res.Error = classifyDNSError(err)
+		return res
+	}
+
+	minRecords := t.DNS.MinRecords
+	if minRecords <= 0 {
+		minRecords = 1
+	}
+	if len(records) == 0 && recordType == "CNAME" {
+		res.Validation = fmt.Sprintf("dns: no CNAME record for %s", host)
+		return res
+	}
+	if len(records) < minRecords {
+		res.Validation = fmt.Sprintf("dns: got %d %s records want >= %d", len(records), recordType, minRecords)
+		return res
+	}
+
+	have := make(map[string]struct{}, len(records))
+	for _, r := range records {
+		have[normalizeDNSValue(recordType, r)] = struct{}{}
+	}
+	for _, want := range t.DNS.Expect {
+		if _, ok := have[normalizeDNSValue(recordType, want)]; !ok {
+			res.Validation = fmt.Sprintf("dns: missing expected %s record %q (got %s)", recordType, want, strings.Join(records, ", "))
+			return res
+		}
+	}
+
+	res.Up = true
+	return res
+}
+
+// newResolver returns the system resolver, or one that sends every query
+// to addr (host or host:port, port defaults to 53).
+func newResolver(addr string) *net.Resolver {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return net.DefaultResolver
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "53")
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// lookupRecords resolves host for a single record type and returns the values as strings.
+func lookupRecords(ctx context.Context, r *net.Resolver, recordType, host string) ([]string, error) {
+	var out []string
+
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := r.LookupIP(ctx, network, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			out = append(out, ip.String())
+		}
+	case "CNAME":
+		cname, err := r.LookupCNAME(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		// Without a CNAME the resolver answers with the queried name itself.
+		if normalizeDNSValue("CNAME", cname) == normalizeDNSValue("CNAME", host) {
+			return nil, nil
+		}
+		out = append(out, strings.TrimSuffix(cname, "."))
+	case "MX":
+		mxs, err := r.LookupMX(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			out = append(out, strings.TrimSuffix(mx.Host, "."))
+		}
+	case "TXT":
+		txts, err := r.LookupTXT(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, txts...)
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+
+	return out, nil
+}
+
+// normalizeDNSValue makes expected and resolved values comparable
+// (IP canonical form, case-insensitive names without trailing dot).
+func normalizeDNSValue(recordType, v string) string {
+	v = strings.TrimSpace(v)
+	switch recordType {
+	case "A", "AAAA":
+		if ip := net.ParseIP(v); ip != nil {
+			return ip.String()
+		}
+	case "CNAME", "MX":
+		return strings.ToLower(strings.TrimSuffix(v, "."))
+	}
+	return v
+}
+
+// dnsHost extracts the name to resolve from a dns:// URL.
+func dnsHost(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("parse url: %v", err)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("invalid dns url %q", raw)
+	}
+	return u.Hostname(), nil
+}
+
+// classifyDNSError turns resolver errors into short, stable reasons.
+func classifyDNSError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		switch {
+		case dnsErr.IsNotFound:
+			return fmt.Sprintf("dns: no such host %s", dnsErr.Name)
+		case dnsErr.IsTimeout:
+			return "dns: timeout"
+		default:
+			return fmt.Sprintf("dns: %s", dnsErr.Err)
+		}
+	}
+	return classifyHTTPError(err)
+}
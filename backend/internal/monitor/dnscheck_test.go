@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeDNS serves a fixed zone over UDP on a local port and returns its address.
+// Names not in the zone are answered with NXDOMAIN.
+func fakeDNS(t *testing.T) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	name := func(s string) dnsmessage.Name { return dnsmessage.MustNewName(s) }
+	hdr := func(n string, typ dnsmessage.Type) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: name(n), Type: typ, Class: dnsmessage.ClassINET, TTL: 60}
+	}
+	app := []dnsmessage.Resource{
+		{Header: hdr("app.example.test.", dnsmessage.TypeA), Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}}},
+		{Header: hdr("app.example.test.", dnsmessage.TypeA), Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}}},
+	}
+	zone := map[string]map[dnsmessage.Type][]dnsmessage.Resource{
+		"app.example.test.": {dnsmessage.TypeA: app},
+		"www.example.test.": {dnsmessage.TypeA: append([]dnsmessage.Resource{
+			{Header: hdr("www.example.test.", dnsmessage.TypeCNAME), Body: &dnsmessage.CNAMEResource{CNAME: name("app.example.test.")}},
+		}, app...)},
+		"example.test.": {
+			dnsmessage.TypeMX:  {{Header: hdr("example.test.", dnsmessage.TypeMX), Body: &dnsmessage.MXResource{Pref: 10, MX: name("mx1.example.test.")}}},
+			dnsmessage.TypeTXT: {{Header: hdr("example.test.", dnsmessage.TypeTXT), Body: &dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}}}},
+		},
+	}
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var req dnsmessage.Message
+			if err := req.Unpack(buf[:n]); err != nil || len(req.Questions) != 1 {
+				continue
+			}
+			q := req.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: req.ID, Response: true, Authoritative: true, RecursionDesired: req.RecursionDesired, RecursionAvailable: true},
+				Questions: req.Questions,
+			}
+			records, ok := zone[strings.ToLower(q.Name.String())]
+			if ok {
+				resp.Answers = records[q.Type]
+			} else {
+				resp.RCode = dnsmessage.RCodeNameError
+			}
+			out, err := resp.Pack()
+			if err != nil {
+				t.Errorf("pack dns response: %v", err)
+				return
+			}
+			pc.WriteTo(out, addr)
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+func TestDNSChecker(t *testing.T) {
+	resolver := fakeDNS(t)
+
+	tests := []struct {
+		name      string
+		url       string
+		opts      DNSOptions
+		wantUp    bool
+		wantError string // substring of Error
+		wantValid string // substring of Validation
+	}{
+		{name: "A records", url: "dns://app.example.test", wantUp: true},
+		{name: "port is not part of the name", url: "dns://app.example.test:53", wantUp: true},
+		{name: "A expected values", url: "dns://app.example.test", opts: DNSOptions{Expect: []string{"10.0.0.2", "10.0.0.1"}}, wantUp: true},
+		{name: "A missing value", url: "dns://app.example.test", opts: DNSOptions{Expect: []string{"10.0.0.3"}}, wantValid: `missing expected A record "10.0.0.3"`},
+		{name: "too few records", url: "dns://app.example.test", opts: DNSOptions{MinRecords: 3}, wantValid: "got 2 A records want >= 3"},
+		{name: "CNAME", url: "dns://www.example.test", opts: DNSOptions{RecordType: "cname", Expect: []string{"APP.example.test."}}, wantUp: true},
+		{name: "no CNAME", url: "dns://app.example.test", opts: DNSOptions{RecordType: "CNAME"}, wantValid: "no CNAME record for app.example.test"},
+		{name: "MX", url: "dns://example.test", opts: DNSOptions{RecordType: "MX", Expect: []string{"mx1.example.test"}}, wantUp: true},
+		{name: "TXT", url: "dns://example.test", opts: DNSOptions{RecordType: "TXT", Expect: []string{"v=spf1 -all"}}, wantUp: true},
+		{name: "NXDOMAIN", url: "dns://missing.example.test", wantError: "dns: no such host"},
+		{name: "unsupported type", url: "dns://example.test", opts: DNSOptions{RecordType: "SRV"}, wantError: `unsupported record type "SRV"`},
+		{name: "no host", url: "dns://", wantError: "invalid dns url"},
+	}
+
+	c := NewDNSChecker()
+	for _, tt := range tests {
+		tt.opts.Resolver = resolver
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		res := c.Check(ctx, Target{Name: "t", URL: tt.url, DNS: tt.opts})
+		cancel()
+
+		if res.Up != tt.wantUp {
+			t.Errorf("%s: up = %v, want %v (error %q, validation %q)", tt.name, res.Up, tt.wantUp, res.Error, res.Validation)
+		}
+		if !strings.Contains(res.Error, tt.wantError) || (tt.wantError == "") != (res.Error == "") {
+			t.Errorf("%s: error %q, want %q", tt.name, res.Error, tt.wantError)
+		}
+		if !strings.Contains(res.Validation, tt.wantValid) || (tt.wantValid == "") != (res.Validation == "") {
+			t.Errorf("%s: validation %q, want %q", tt.name, res.Validation, tt.wantValid)
+		}
+	}
+}
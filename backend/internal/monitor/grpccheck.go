@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCChecker is the Checker for "grpc" targets (url: grpc://host:port).
+// It calls the standard grpc.health.v1.Health/Check; SERVING is UP, any other
+// serving status (or an RPC error) is DOWN.
+type GRPCChecker struct{}
+
+// NewGRPCChecker returns a gRPC health checker.
+func NewGRPCChecker() *GRPCChecker {
+	return &GRPCChecker{}
+}
+
+func (c *GRPCChecker) Check(ctx context.Context, t Target) CheckResult {
+	start := time.Now()
+
+	res := CheckResult{
+		TargetName: t.Name,
+		URL:        t.URL,
+		At:         time.Now(),
+		Attempt:    1,
+	}
+
+	u, err := url.Parse(t.URL)
+	if err != nil || u.Host == "" {
+		res.Error = fmt.Sprintf("invalid grpc url %q", t.URL)
+		res.Latency = time.Since(start)
+		return res
+	}
+
+	creds := insecure.NewCredentials()
+	if t.GRPC.TLS {
+		creds = credentials.NewTLS(&tls.Config{
+			ServerName:         u.Hostname(),
+			InsecureSkipVerify: t.GRPC.InsecureSkipVerify,
+		})
+	}
+
+	// A fresh connection per check so every probe measures a real connect.
+	conn, err := grpc.NewClient(u.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		res.Error = fmt.Sprintf("grpc: %v", err)
+		res.Latency = time.Since(start)
+		return res
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: t.GRPC.Service})
+	res.Latency = time.Since(start)
+	if err != nil {
+		res.Error = classifyGRPCError(err)
+		return res
+	}
+
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		res.Validation = fmt.Sprintf("grpc health: %s", resp.GetStatus())
+		return res
+	}
+
+	res.Up = true
+	return res
+}
+
+// classifyGRPCError maps RPC failures to stable reasons, e.g. "timeout" or
+// "grpc Unavailable: connection refused".
+func classifyGRPCError(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return classifyHTTPError(err)
+	}
+	switch st.Code() {
+	case codes.DeadlineExceeded:
+		return "timeout"
+	case codes.Canceled:
+		return "canceled"
+	case codes.Unimplemented:
+		return "grpc: health service not implemented"
+	}
+	return fmt.Sprintf("grpc %s: %s", st.Code(), st.Message())
+}
@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthServer runs a gRPC server on a local port; withHealth registers the
+// standard health service with the given per-service statuses.
+func healthServer(t *testing.T, withHealth bool, statuses map[string]healthpb.HealthCheckResponse_ServingStatus) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+	if withHealth {
+		hs := health.NewServer()
+		for svc, st := range statuses {
+			hs.SetServingStatus(svc, st)
+		}
+		healthpb.RegisterHealthServer(srv, hs)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+	return ln.Addr().String()
+}
+
+func TestGRPCChecker(t *testing.T) {
+	addr := healthServer(t, true, map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"orders":   healthpb.HealthCheckResponse_SERVING,
+		"payments": healthpb.HealthCheckResponse_NOT_SERVING,
+	})
+	bare := healthServer(t, false, nil)
+
+	tests := []struct {
+		name      string
+		url       string
+		service   string
+		wantUp    bool
+		wantError string // substring of Error
+		wantValid string // substring of Validation
+	}{
+		{name: "server health", url: "grpc://" + addr, wantUp: true},
+		{name: "service serving", url: "grpc://" + addr, service: "orders", wantUp: true},
+		{name: "service not serving", url: "grpc://" + addr, service: "payments", wantValid: "grpc health: NOT_SERVING"},
+		{name: "unknown service", url: "grpc://" + addr, service: "nope", wantError: "grpc NotFound"},
+		{name: "no health service", url: "grpc://" + bare, wantError: "health service not implemented"},
+		{name: "connection refused", url: "grpc://" + closedAddr(t), wantError: "grpc Unavailable"},
+		{name: "no host", url: "grpc://", wantError: "invalid grpc url"},
+	}
+
+	c := NewGRPCChecker()
+	for _, tt := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		res := c.Check(ctx, Target{Name: "t", URL: tt.url, GRPC: GRPCOptions{Service: tt.service}})
+		cancel()
+
+		if res.Up != tt.wantUp {
+			t.Errorf("%s: up = %v, want %v (error %q, validation %q)", tt.name, res.Up, tt.wantUp, res.Error, res.Validation)
+		}
+		if !strings.Contains(res.Error, tt.wantError) || (tt.wantError == "") != (res.Error == "") {
+			t.Errorf("%s: error %q, want %q", tt.name, res.Error, tt.wantError)
+		}
+		if !strings.Contains(res.Validation, tt.wantValid) || (tt.wantValid == "") != (res.Validation == "") {
+			t.Errorf("%s: validation %q, want %q", tt.name, res.Validation, tt.wantValid)
+		}
+	}
+}
+
+func TestGRPCCheckerNotGRPC(t *testing.T) {
+	// Accepts connections and hangs up without speaking HTTP/2.
+	addr := bannerServer(t, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	res := NewGRPCChecker().Check(ctx, Target{Name: "t", URL: "grpc://" + addr})
+	if res.Up || res.Error == "" {
+		t.Errorf("want a failure from a server that isn't gRPC, got up %v error %q", res.Up, res.Error)
+	}
+}
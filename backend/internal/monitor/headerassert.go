@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Header assertion operators.
+const (
+	HeaderOpExact   = "exact"
+	HeaderOpRegex   = "regex"
+	HeaderOpPresent = "present"
+	HeaderOpAbsent  = "absent"
+)
+
+// HeaderAssertion checks one response header.
+type HeaderAssertion struct {
+	Name    string
+	Op      string
+	Value   string         // expected value for exact
+	Pattern *regexp.Regexp // compiled pattern for regex
+}
+
+// checkHeaders runs every assertion in order and returns "" when all pass,
+// otherwise a description of the first mismatch.
+func checkHeaders(h http.Header, asserts []HeaderAssertion) string {
+	for _, a := range asserts {
+		values := h.Values(a.Name)
+
+		switch a.Op {
+		case HeaderOpPresent:
+			if len(values) == 0 {
+				return fmt.Sprintf("header %s missing", a.Name)
+			}
+		case HeaderOpAbsent:
+			if len(values) > 0 {
+				return fmt.Sprintf("header %s present: %q", a.Name, values[0])
+			}
+		case HeaderOpExact:
+			if len(values) == 0 {
+				return fmt.Sprintf("header %s missing (want %q)", a.Name, a.Value)
+			}
+			if !anyValue(values, func(v string) bool { return strings.TrimSpace(v) == a.Value }) {
+				return fmt.Sprintf("header %s: got %q want %q", a.Name, values[0], a.Value)
+			}
+		case HeaderOpRegex:
+			if len(values) == 0 {
+				return fmt.Sprintf("header %s missing (want match %q)", a.Name, a.Pattern.String())
+			}
+			if !anyValue(values, a.Pattern.MatchString) {
+				return fmt.Sprintf("header %s: %q does not match %q", a.Name, values[0], a.Pattern.String())
+			}
+		default:
+			return fmt.Sprintf("header %s: unknown operator %q", a.Name, a.Op)
+		}
+	}
+	return ""
+}
+
+func anyValue(values []string, ok func(string) bool) bool {
+	for _, v := range values {
+		if ok(v) {
+			return true
+		}
+	}
+	return false
+}
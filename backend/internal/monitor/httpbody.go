@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/unicode/norm"
+)
+
+// readBody reads at most maxBytes of decoded body and returns it as NFC-normalized UTF-8.
+// truncated reports whether the body was longer than maxBytes.
+//
+//   - gzip is decoded here when the transport didn't do it for us (e.g. a custom
+//     Accept-Encoding header was sent); the limit applies to the decompressed bytes.
+//   - Non-UTF-8 charsets from Content-Type (e.g. windows-1253 / ISO-8859-7 on older
+//     Greek sites) are converted so keyword and regex checks see real text.
+func readBody(resp *http.Response, maxBytes int64) (body []byte, truncated bool, err error) {
+	var r io.Reader = resp.Body
+
+	if !resp.Uncompressed && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, false, fmt.Errorf("gzip: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	// One byte past the limit tells a cut-off body from one that fits exactly.
+	b, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(b)) > maxBytes {
+		b, truncated = b[:maxBytes], true
+	}
+
+	return norm.NFC.Bytes(toUTF8(b, resp.Header.Get("Content-Type"))), truncated, nil
+}
+
+// toUTF8 converts b from the charset declared in contentType. Unknown or
+// missing charsets are assumed to be UTF-8 and returned unchanged.
+func toUTF8(b []byte, contentType string) []byte {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return b
+	}
+	cs := strings.ToLower(strings.TrimSpace(params["charset"]))
+	if cs == "" || cs == "utf-8" || cs == "utf8" {
+		return b
+	}
+
+	enc, err := htmlindex.Get(cs)
+	if err != nil {
+		return b
+	}
+	out, err := enc.NewDecoder().Bytes(b)
+	if err != nil {
+		return b
+	}
+	return out
+}
@@ -2,28 +2,66 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
 	"time"
+
+	"golang.org/x/text/unicode/norm"
 )
 
+// HTTPChecker is the Checker for "http" targets.
+type HTTPChecker struct {
+	client *http.Client
+}
+
+// NewHTTPChecker returns an HTTP checker that issues requests through client.
+func NewHTTPChecker(client *http.Client) *HTTPChecker {
+	return &HTTPChecker{client: client}
+}
+
+func (c *HTTPChecker) Check(ctx context.Context, t Target) CheckResult {
+	return CheckOnce(ctx, c.client, t)
+}
+
 // CheckOnce performs a single HTTP check for a target.
 // - Uses ctx for cancellation/timeout (workers should pass a per-job context.WithTimeout).
 // - Measures total request latency.
 // - Validates expected status and optional keyword match.
 func CheckOnce(ctx context.Context, client *http.Client, t Target) CheckResult {
+	return checkHTTP(ctx, client, t, nil)
+}
+
+// httpExchange captures response data for callers that need more than the
+// verdict (multi-step checks extracting variables). wantBody forces a body read
+// even when t has no body checks.
+type httpExchange struct {
+	wantBody bool
+
+	header http.Header
+	body   []byte
+}
+
+// checkHTTP is CheckOnce with an optional exchange to fill in.
+func checkHTTP(ctx context.Context, client *http.Client, t Target, ex *httpExchange) (res CheckResult) {
 	start := time.Now()
 
-	res := CheckResult{
+	// Phase timings are attached on every return path that got a response.
+	rec := newTraceRecorder()
+	ctx = httptrace.WithClientTrace(ctx, rec.clientTrace())
+	defer func() { res.Timing = rec.result() }()
+
+	res = CheckResult{
 		TargetName: t.Name,
 		URL:        t.URL,
 		At:         time.Now(),
 		Attempt:    1,
 	}
 
-	req, err := http.NewRequestWithContext(ctx, t.Method, t.URL, nil)
+	req, err := newCheckRequest(ctx, t)
 	if err != nil {
 		res.Up = false
 		res.Error = fmt.Sprintf("build request: %v", err)
@@ -36,7 +74,10 @@ func CheckOnce(ctx context.Context, client *http.Client, t Target) CheckResult {
 		req.Header.Set("User-Agent", "CyprusStatusMonitor/0.1")
 	}
 
-	resp, err := client.Do(req)
+	resp, err := withRedirectPolicy(client, t, &res).Do(req)
+	// Certificate details are recorded for the requested host on every check,
+	// including ones that failed verification.
+	res.TLS = inspectTLS(rec.requestedTLS(err), req.URL.Hostname(), t.TLS.ExpiryWarningDays, time.Now())
 	if err != nil {
 		res.Up = false
 		res.Error = classifyHTTPError(err)
@@ -44,53 +85,86 @@ func CheckOnce(ctx context.Context, client *http.Client, t Target) CheckResult {
 		return res
 	}
 	defer resp.Body.Close()
-	
+
+	maxBytes := t.MaxBodyBytes
+	if maxBytes <= 0 {
+		maxBytes = 64 * 1024 // 64KB default safety
+	}
+
+	if ex != nil {
+		ex.header = resp.Header
+	}
+
 	res.StatusCode = resp.StatusCode
 	res.Latency = time.Since(start)
+	res.FinalURL = resp.Request.URL.String()
 
-	// 1) Status code validation
-	if t.ExpectedStatus != 0 && resp.StatusCode != t.ExpectedStatus {
+	// 1) Status code validation (no expected status configured => 200-399 is UP)
+	if !statusAccepted(resp.StatusCode, t.ExpectedStatus) {
 		res.Up = false
-		res.Validation = fmt.Sprintf("unexpected status: got %d want %d", resp.StatusCode, t.ExpectedStatus)
+		res.Validation = fmt.Sprintf("unexpected status: got %d want %s", resp.StatusCode, formatStatusRanges(t.ExpectedStatus))
 		return res
 	}
 
-	// If no expected status provided (or set to 0), consider 200-399 as UP.
-	if t.ExpectedStatus == 0 {
-		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+	// Response header validation (e.g. CDN serving the wrong Content-Type)
+	if len(t.ExpectHeaders) > 0 {
+		if msg := checkHeaders(resp.Header, t.ExpectHeaders); msg != "" {
 			res.Up = false
-			res.Validation = fmt.Sprintf("bad status: %d", resp.StatusCode)
+			res.Validation = msg
 			return res
 		}
 	}
 
-	// 2) Keyword/content validation (GET only)
-	contains := strings.TrimSpace(t.Contains)
-	if contains != "" {
-		if strings.ToUpper(t.Method) == "HEAD" {
+	// Redirect target validation (e.g. site now redirects to a parked-domain page)
+	if t.ExpectedFinalURL != "" && !sameURL(res.FinalURL, t.ExpectedFinalURL) {
+		res.Up = false
+		res.Validation = fmt.Sprintf("%s: got %s want %s", FinalURLMismatch, res.FinalURL, t.ExpectedFinalURL)
+		return res
+	}
+
+	// 2) Body validations (not HEAD): keyword, pattern and JSON checks share one bounded read.
+	if t.hasBodyChecks() || (ex != nil && ex.wantBody) {
+		if t.hasBodyChecks() && strings.ToUpper(t.Method) == "HEAD" {
 			res.Up = false
-			res.Validation = "contains check configured but method is HEAD (no body)"
+			res.Validation = "body check configured but method is HEAD (no body)"
 			return res
 		}
 
-		maxBytes := t.MaxBodyBytes
-		if maxBytes <= 0 {
-			maxBytes = 64 * 1024 // 64KB default safety
-		}
-
-		limited := io.LimitReader(resp.Body, maxBytes)
-		bodyBytes, readErr := io.ReadAll(limited)
+		bodyBytes, truncated, readErr := readBody(resp, maxBytes)
+		rec.markBodyDone()
 		if readErr != nil {
 			res.Up = false
 			res.Error = fmt.Sprintf("read body: %v", readErr)
 			return res
 		}
+		if ex != nil {
+			ex.body = bodyBytes
+		}
+		if t.WatchContent != nil {
+			res.Content = t.WatchContent.snapshot(bodyBytes, resp.Header.Get("Content-Type"))
+			if res.Content != nil {
+				res.Content.Truncated = truncated
+			}
+		}
 
-		if !strings.Contains(string(bodyBytes), contains) {
+		if msg := checkBodyContent(string(bodyBytes), t); msg != "" {
 			res.Up = false
-			res.Validation = fmt.Sprintf("keyword missing: %q", contains)
+			res.Validation = msg
 			return res
 		}
+
+		if len(t.JSONAssert) > 0 {
+			if msg := evalJSONAssertions(bodyBytes, t.JSONAssert); msg != "" {
+				res.Up = false
+				res.Validation = msg
+				return res
+			}
+		}
+	} else if strings.ToUpper(t.Method) != "HEAD" {
+		// No body checks: still drain (bounded) so Transfer is measured and the
+		// connection can be reused.
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxBytes))
+		rec.markBodyDone()
 	}
 
 	// Passed all validations
@@ -100,6 +174,103 @@ func CheckOnce(ctx context.Context, client *http.Client, t Target) CheckResult {
 	return res
 }
 
+// FinalURLMismatch prefixes the validation message when expected_final_url fails.
+const FinalURLMismatch = "final url mismatch"
+
+// tooManyRedirectsError is returned from CheckRedirect when a target exceeds MaxRedirects.
+type tooManyRedirectsError struct {
+	max int
+}
+
+func (e *tooManyRedirectsError) Error() string {
+	return fmt.Sprintf("too many redirects (max %d)", e.max)
+}
+
+// withRedirectPolicy returns a shallow copy of client (sharing its transport)
+// that applies t's redirect settings and records every hop it follows into
+// res.Redirects.
+func withRedirectPolicy(client *http.Client, t Target, res *CheckResult) *http.Client {
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !t.FollowRedirects {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > t.MaxRedirects {
+			return &tooManyRedirectsError{max: t.MaxRedirects}
+		}
+		if prev := req.Response; prev != nil {
+			res.Redirects = append(res.Redirects, RedirectHop{
+				StatusCode: prev.StatusCode,
+				URL:        via[len(via)-1].URL.String(),
+				Location:   prev.Header.Get("Location"),
+			})
+		}
+		return nil
+	}
+	return &c
+}
+
+// sameURL compares two URLs ignoring a trailing slash.
+func sameURL(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+// newCheckRequest builds the request for t: method, body, headers and auth.
+func newCheckRequest(ctx context.Context, t Target) (*http.Request, error) {
+	var body io.Reader
+	if t.Body != "" {
+		body = strings.NewReader(t.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.Method, t.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range t.Headers {
+		if strings.EqualFold(k, "Host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+
+	switch {
+	case t.BasicAuth != nil:
+		req.SetBasicAuth(t.BasicAuth.Username, t.BasicAuth.Password)
+	case t.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+t.BearerToken)
+	}
+
+	return req, nil
+}
+
+// checkBodyContent runs the keyword and regex checks against the decoded body.
+// Returns "" when all pass, otherwise the first failure.
+func checkBodyContent(body string, t Target) string {
+	if contains := strings.TrimSpace(t.Contains); contains != "" {
+		if !strings.Contains(body, norm.NFC.String(contains)) {
+			return fmt.Sprintf("keyword missing: %q", contains)
+		}
+	}
+	for _, re := range t.Matches {
+		if !re.MatchString(body) {
+			return fmt.Sprintf("pattern not matched: %q", re.String())
+		}
+	}
+	for _, kw := range t.NotContains {
+		if strings.Contains(body, norm.NFC.String(kw)) {
+			return fmt.Sprintf("forbidden keyword present: %q", kw)
+		}
+	}
+	for _, re := range t.NotMatches {
+		if re.MatchString(body) {
+			return fmt.Sprintf("forbidden pattern matched: %q", re.String())
+		}
+	}
+	return ""
+}
+
 // classifyHTTPError tries to produce a stable, human-readable reason.
 // Keep it simple for MVP (don’t over-engineer).
 func classifyHTTPError(err error) string {
@@ -110,6 +281,13 @@ func classifyHTTPError(err error) string {
 	if errorsIsContextCanceled(err) {
 		return "canceled"
 	}
+	var redirectErr *tooManyRedirectsError
+	if errors.As(err, &redirectErr) {
+		return redirectErr.Error()
+	}
+	if reason := classifyTLSError(err); reason != "" {
+		return reason
+	}
 	// The raw error string is useful for debugging; you can refine later.
 	return err.Error()
 }
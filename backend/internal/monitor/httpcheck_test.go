@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHTTPCheckRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/a", http.RedirectHandler("/b", http.StatusMovedPermanently))
+	mux.Handle("/b", http.RedirectHandler("/c", http.StatusFound))
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		follow    bool
+		max       int
+		wantHops  []string // "code path -> location"
+		wantFinal string   // path, "" when the check fails
+	}{
+		{name: "not followed", wantFinal: "/a"},
+		{name: "followed", follow: true, max: 5, wantHops: []string{"301 /a -> /b", "302 /b -> /c"}, wantFinal: "/c"},
+		{name: "too many", follow: true, max: 1, wantHops: []string{"301 /a -> /b"}},
+	}
+
+	for _, tt := range tests {
+		target := Target{Name: "t", URL: srv.URL + "/a", FollowRedirects: tt.follow, MaxRedirects: tt.max}
+		res := CheckOnce(context.Background(), srv.Client(), target)
+
+		var hops []string
+		for _, h := range res.Redirects {
+			hops = append(hops, fmt.Sprintf("%d %s -> %s", h.StatusCode, strings.TrimPrefix(h.URL, srv.URL), h.Location))
+		}
+		if !reflect.DeepEqual(hops, tt.wantHops) {
+			t.Errorf("%s: hops %q, want %q", tt.name, hops, tt.wantHops)
+		}
+		if got := strings.TrimPrefix(res.FinalURL, srv.URL); got != tt.wantFinal {
+			t.Errorf("%s: final url %q, want %q", tt.name, got, tt.wantFinal)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/go-telegram/bot"
@@ -11,38 +12,83 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Incident kinds (incidents.kind). DEGRADED incidents are lower severity and
+// can overlap with DOWN ones. A FLAPPING incident spans the flapping window and
+// overlaps the DOWN incidents recorded inside it.
+const (
+	IncidentDown     = "DOWN"
+	IncidentDegraded = "DEGRADED"
+	IncidentFlapping = "FLAPPING"
+)
+
 // IncidentCollector listens to events and records incident lifecycles in the DB.
 // An incident starts when a target transitions from UP->DOWN (or TIMEOUT), and
-// ends when it returns to UP. Only one open incident per (target, probe) exists.
+// ends when it returns to UP. Slow-but-working periods are recorded as separate
+// DEGRADED incidents. Only one open incident per (target, probe, kind) exists.
+// While a target is flapping its transitions are still recorded, but only the
+// flapping started/stopped notifications are sent. Outages caused by a DOWN
+// parent (depends_on) are recorded with caused_by and not notified. Targets
+// with a quorum get one incident under probe "quorum", listing in probes every
+// probe that saw the failure.
 func IncidentCollector(ctx context.Context, eventsCh <-chan Event, dbpool *pgxpool.Pool, tbot *bot.Bot, chatID int64) {
 	go func() {
 		for e := range eventsCh {
 			if dbpool == nil {
 				continue
 			}
+			if e.Kind == EventCertExpiring {
+				// Warning only: no incident, just a heads-up.
+				sendTelegram(ctx, tbot, chatID, e.TargetName, formatTelegramCertMessage(e))
+				continue
+			}
+			if e.Kind == EventContentChanged {
+				// Independent of UP/DOWN: a possible defacement needs a human look.
+				sendTelegram(ctx, tbot, chatID, e.TargetName, formatTelegramContentMessage(e))
+				continue
+			}
 			if err := persistIncident(ctx, dbpool, e); err != nil {
 				log.Printf("incident persist failed for %s: %v", e.TargetName, err)
 			}
 
-			if tbot != nil {
-				var msg string
-				if !e.To {
-					msg = formatTelegramDownMessage(e)
-				} else {
-					msg = formatTelegramUpMessage(e)
-				}
-
-				if _, err := tbot.SendMessage(ctx, &bot.SendMessageParams{
-					ChatID: chatID,
-					Text:   msg,
-				}); err != nil {
-					log.Printf("telegram send failed for %s: %v", e.TargetName, err)
+			var msg string
+			switch {
+			case e.Kind == EventTransition && (e.Flapping || e.CausedBy != ""):
+				continue
+			case e.Kind == EventFlappingStarted:
+				msg = formatTelegramFlappingMessage(e)
+			case e.Kind == EventFlappingStopped:
+				msg = formatTelegramFlappingStoppedMessage(e)
+			case e.Kind == EventDegraded:
+				msg = formatTelegramDegradedMessage(e)
+			case e.Kind == EventDegradedRecovered:
+				// Going DEGRADED -> DOWN: the DOWN alert covers it.
+				if e.ToStatus == StatusDown {
+					continue
 				}
+				msg = formatTelegramRecoveredMessage(e)
+			case !e.To:
+				msg = formatTelegramDownMessage(e)
+			default:
+				msg = formatTelegramUpMessage(e)
 			}
+			sendTelegram(ctx, tbot, chatID, e.TargetName, msg)
 		}
 	}()
 }
 
+// sendTelegram delivers msg if notifications are enabled (tbot != nil).
+func sendTelegram(ctx context.Context, tbot *bot.Bot, chatID int64, target, msg string) {
+	if tbot == nil {
+		return
+	}
+	if _, err := tbot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   msg,
+	}); err != nil {
+		log.Printf("telegram send failed for %s: %v", target, err)
+	}
+}
+
 func formatTelegramDownMessage(ev Event) string {
 	statusLine := "Status: "
 	switch {
@@ -60,9 +106,17 @@ func formatTelegramDownMessage(ev Event) string {
 		statusLine += fmt.Sprintf(" — %s", ev.Reason)
 	}
 
-	return fmt.Sprintf("🚨 DOWN: %s\n%s\nProbe: primary\nAt: %s",
+	if strings.HasPrefix(ev.Reason, FinalURLMismatch) {
+		statusLine += "\nRedirects:"
+		for _, hop := range ev.Redirects {
+			statusLine += fmt.Sprintf("\n  %d %s → %s", hop.StatusCode, hop.URL, hop.Location)
+		}
+	}
+
+	return fmt.Sprintf("🚨 DOWN: %s\n%s\n%s\nAt: %s",
 		ev.TargetName,
 		statusLine,
+		probeLine(ev),
 		ev.At.UTC().Format("2006-01-02 15:04 MST"),
 	)
 }
@@ -78,51 +132,170 @@ func formatTelegramUpMessage(ev Event) string {
 		}
 	}
 
-	return fmt.Sprintf("✅ UP: %s\n%s\nProbe: primary\nAt: %s",
+	return fmt.Sprintf("✅ UP: %s\n%s\n%s\nAt: %s",
 		ev.TargetName,
 		statusLine,
+		probeLine(ev),
+		ev.At.UTC().Format("2006-01-02 15:04 MST"),
+	)
+}
+
+func formatTelegramDegradedMessage(ev Event) string {
+	return fmt.Sprintf("🐢 DEGRADED: %s\nLatency: %s\n%s\n%s\nAt: %s",
+		ev.TargetName,
+		ev.Latency.Round(time.Millisecond),
+		ev.Reason,
+		probeLine(ev),
+		ev.At.UTC().Format("2006-01-02 15:04 MST"),
+	)
+}
+
+func formatTelegramRecoveredMessage(ev Event) string {
+	return fmt.Sprintf("👌 RECOVERED: %s\nLatency: %s\n%s\nAt: %s",
+		ev.TargetName,
+		ev.Latency.Round(time.Millisecond),
+		probeLine(ev),
 		ev.At.UTC().Format("2006-01-02 15:04 MST"),
 	)
 }
 
+func formatTelegramCertMessage(ev Event) string {
+	msg := fmt.Sprintf("⚠️ CERT EXPIRING: %s\n", ev.TargetName)
+	if ev.TLS != nil {
+		msg += fmt.Sprintf("Expires: %s (%d days)\nIssuer: %s\n",
+			ev.TLS.NotAfter.UTC().Format("2006-01-02"),
+			ev.TLS.DaysToExpiry(ev.At),
+			ev.TLS.Issuer,
+		)
+	}
+	return msg + fmt.Sprintf("%s\nAt: %s", probeLine(ev), ev.At.UTC().Format("2006-01-02 15:04 MST"))
+}
+
+func formatTelegramFlappingMessage(ev Event) string {
+	return fmt.Sprintf("🔁 FLAPPING: %s\n%s since %s\nUP/DOWN alerts paused until it settles.\n%s\nAt: %s",
+		ev.TargetName,
+		ev.Reason,
+		ev.FlappingFrom.UTC().Format("15:04 MST"),
+		probeLine(ev),
+		ev.At.UTC().Format("2006-01-02 15:04 MST"),
+	)
+}
+
+func formatTelegramFlappingStoppedMessage(ev Event) string {
+	return fmt.Sprintf("🟰 FLAPPING STOPPED: %s\nNow: %s\nFlapped for %s (%s)\n%s\nAt: %s",
+		ev.TargetName,
+		statusFromEvent(ev),
+		ev.At.Sub(ev.FlappingFrom).Round(time.Minute),
+		ev.Reason,
+		probeLine(ev),
+		ev.At.UTC().Format("2006-01-02 15:04 MST"),
+	)
+}
+
+func formatTelegramContentMessage(ev Event) string {
+	return fmt.Sprintf("📝 CONTENT CHANGED: %s\n%s\nChanges:\n%s\n%s\nAt: %s",
+		ev.TargetName,
+		ev.URL,
+		ev.Diff,
+		probeLine(ev),
+		ev.At.UTC().Format("2006-01-02 15:04 MST"),
+	)
+}
+
+// probeLine names where the event was seen: the single probe, or for quorum
+// decisions every probe that saw the target DOWN.
+func probeLine(ev Event) string {
+	if len(ev.Probes) > 0 {
+		return "Probes: " + strings.Join(ev.Probes, ", ")
+	}
+	return "Probe: " + ev.Probe
+}
+
 // persistIncident upserts incidents table according to transition events.
 func persistIncident(ctx context.Context, db *pgxpool.Pool, ev Event) error {
-	// When we go DOWN -> open incident; when we go UP -> close existing.
-	if ev.To == false { // going down
+	kind, opening := incidentAction(ev)
+
+	// When we go DOWN (or DEGRADED) -> open incident; when we leave it -> close existing.
+	if opening {
+		// A flapping incident covers the whole window, from its first transition.
+		startedAt := ev.At
+		if ev.Kind == EventFlappingStarted && !ev.FlappingFrom.IsZero() {
+			startedAt = ev.FlappingFrom
+		}
+
 		// Insert only if there isn't an active (ended_at IS NULL) incident already.
 		_, err := db.Exec(ctx, `
             INSERT INTO incidents (
-                target_name, probe,
+                target_name, probe, kind,
                 started_at,
                 start_status,
                 start_status_code,
-                start_error
+                start_error,
+                caused_by,
+                probes
             )
-            SELECT $1, $2, $3, $4, NULLIF($5,0), NULLIF($6,'')
+            SELECT $1, $2, $3, $4, $5, NULLIF($6,0), NULLIF($7,''), NULLIF($8,''), $9::text[]
             WHERE NOT EXISTS (
-                SELECT 1 FROM incidents WHERE target_name = $1 AND probe = $2 AND ended_at IS NULL
+                SELECT 1 FROM incidents WHERE target_name = $1 AND probe = $2 AND kind = $3 AND ended_at IS NULL
             )
-        `, ev.TargetName, "primary", ev.At, statusFromEvent(ev), ev.StatusCode, ev.Reason)
+        `, ev.TargetName, ev.Probe, kind, startedAt, statusFromEvent(ev), ev.StatusCode, ev.Reason, ev.CausedBy, ev.Probes)
+		if err != nil || ev.CausedBy != "" {
+			return err
+		}
+
+		// A child released after its parent recovered (see releaseChildren) still
+		// has the incident opened under the parent; from now on it is its own outage.
+		_, err = db.Exec(ctx, `
+            UPDATE incidents
+               SET caused_by = NULL,
+                   updated_at = now()
+             WHERE target_name = $1
+               AND probe = $2
+               AND kind = $3
+               AND ended_at IS NULL
+               AND caused_by IS NOT NULL
+        `, ev.TargetName, ev.Probe, kind)
 		return err
 	}
 
-	// ev.To == true: close any active incident for this target.
+	// Leaving the state: close any active incident of this kind for this target.
 	_, err := db.Exec(ctx, `
         UPDATE incidents
            SET ended_at = $1,
-               end_status = 'UP',
-               end_status_code = NULLIF($2,0),
-               end_error = NULLIF($3,''), 
+               end_status = $2,
+               end_status_code = NULLIF($3,0),
+               end_error = NULLIF($4,''),
+               probes = COALESCE($8::text[], probes),
                updated_at = now()
-         WHERE target_name = $4
-           AND probe = $5
+         WHERE target_name = $5
+           AND probe = $6
+           AND kind = $7
            AND ended_at IS NULL
-    `, ev.At, ev.StatusCode, ev.Reason, ev.TargetName, "primary")
+    `, ev.At, statusFromEvent(ev), ev.StatusCode, ev.Reason, ev.TargetName, ev.Probe, kind, ev.Probes)
 	return err
 }
 
-// statusFromEvent maps the "To" bool into incident status text.
+// incidentAction maps an event to the incident kind it affects and whether it opens or closes it.
+func incidentAction(ev Event) (kind string, opening bool) {
+	switch ev.Kind {
+	case EventDegraded:
+		return IncidentDegraded, true
+	case EventDegradedRecovered:
+		return IncidentDegraded, false
+	case EventFlappingStarted:
+		return IncidentFlapping, true
+	case EventFlappingStopped:
+		return IncidentFlapping, false
+	default:
+		return IncidentDown, !ev.To
+	}
+}
+
+// statusFromEvent maps the event's target state into incident status text.
 func statusFromEvent(ev Event) string {
+	if ev.ToStatus != "" {
+		return ev.ToStatus
+	}
 	if ev.To {
 		return "UP"
 	}
@@ -132,7 +305,7 @@ func statusFromEvent(ev Event) string {
 
 // EventForTest is a helper for tests to avoid importing monitor everywhere.
 func EventForTest(target string, from, to bool, at time.Time) Event {
-	return Event{TargetName: target, From: from, To: to, At: at}
+	return Event{Kind: EventTransition, TargetName: target, From: from, To: to, At: at}
 }
 
 // Ensure pgx.ErrNoRows is linked for potential future uses.
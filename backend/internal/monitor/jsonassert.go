@@ -0,0 +1,210 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSON assertion operators.
+const (
+	JSONOpEquals    = "equals"
+	JSONOpNotEquals = "not_equals"
+	JSONOpExists    = "exists"
+	JSONOpRegex     = "regex"
+	JSONOpGT        = "gt"
+	JSONOpGTE       = "gte"
+	JSONOpLT        = "lt"
+	JSONOpLTE       = "lte"
+)
+
+// JSONAssertion is a single check against a JSON response body.
+// Path uses a JSONPath-like subset: "$.status", "$.checks[0].name", "db.status".
+type JSONAssertion struct {
+	Path    string
+	Op      string
+	Value   string         // expected value as text; numbers are compared numerically
+	Pattern *regexp.Regexp // compiled pattern for regex
+}
+
+// evalJSONAssertions decodes body and runs every assertion in order.
+// It returns "" when all pass, otherwise a description of the first failure.
+func evalJSONAssertions(body []byte, asserts []JSONAssertion) string {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Sprintf("json: invalid body: %v", err)
+	}
+
+	for _, a := range asserts {
+		if msg := evalJSONAssertion(doc, a); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+func evalJSONAssertion(doc any, a JSONAssertion) string {
+	got, found, err := lookupJSONPath(doc, a.Path)
+	if err != nil {
+		return fmt.Sprintf("json: %s: %v", a.Path, err)
+	}
+
+	if a.Op == JSONOpExists {
+		if !found {
+			return fmt.Sprintf("json: %s does not exist", a.Path)
+		}
+		return ""
+	}
+	if !found {
+		return fmt.Sprintf("json: %s %s %q (path not found)", a.Path, a.Op, a.Value)
+	}
+
+	gotText := jsonValueText(got)
+	fail := func() string {
+		return fmt.Sprintf("json: %s %s %q (got %s)", a.Path, a.Op, a.Value, gotText)
+	}
+
+	switch a.Op {
+	case JSONOpEquals:
+		if !jsonValueEquals(gotText, a.Value) {
+			return fail()
+		}
+	case JSONOpNotEquals:
+		if jsonValueEquals(gotText, a.Value) {
+			return fail()
+		}
+	case JSONOpRegex:
+		if a.Pattern == nil || !a.Pattern.MatchString(gotText) {
+			return fail()
+		}
+	case JSONOpGT, JSONOpGTE, JSONOpLT, JSONOpLTE:
+		gotNum, err1 := strconv.ParseFloat(gotText, 64)
+		wantNum, err2 := strconv.ParseFloat(a.Value, 64)
+		if err1 != nil || err2 != nil {
+			return fail()
+		}
+		ok := false
+		switch a.Op {
+		case JSONOpGT:
+			ok = gotNum > wantNum
+		case JSONOpGTE:
+			ok = gotNum >= wantNum
+		case JSONOpLT:
+			ok = gotNum < wantNum
+		case JSONOpLTE:
+			ok = gotNum <= wantNum
+		}
+		if !ok {
+			return fail()
+		}
+	default:
+		return fmt.Sprintf("json: unknown operator %q", a.Op)
+	}
+
+	return ""
+}
+
+// lookupJSONPath walks doc following path. found is false when a key or index is missing.
+func lookupJSONPath(doc any, path string) (v any, found bool, err error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cur := doc
+	for _, seg := range segments {
+		switch node := cur.(type) {
+		case map[string]any:
+			if seg.isIndex {
+				return nil, false, nil
+			}
+			next, ok := node[seg.key]
+			if !ok {
+				return nil, false, nil
+			}
+			cur = next
+		case []any:
+			if !seg.isIndex || seg.index < 0 || seg.index >= len(node) {
+				return nil, false, nil
+			}
+			cur = node[seg.index]
+		default:
+			return nil, false, nil
+		}
+	}
+	return cur, true, nil
+}
+
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath splits "$.a.b[2].c" into segments. A leading "$" is optional.
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+	p = strings.TrimPrefix(p, ".")
+
+	var out []jsonPathSegment
+	for p != "" {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ in path %q", path)
+			}
+			inner := strings.Trim(p[1:end], `"'`)
+			if n, err := strconv.Atoi(inner); err == nil {
+				out = append(out, jsonPathSegment{index: n, isIndex: true})
+			} else {
+				out = append(out, jsonPathSegment{key: inner})
+			}
+			p = p[end+1:]
+		default:
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			out = append(out, jsonPathSegment{key: p[:end]})
+			p = p[end:]
+		}
+	}
+	return out, nil
+}
+
+// jsonValueText renders a decoded JSON value the way it's compared against expected values.
+func jsonValueText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+}
+
+// jsonValueEquals compares numerically when both sides are numbers ("1" == "1.0").
+func jsonValueEquals(got, want string) bool {
+	if got == want {
+		return true
+	}
+	g, err1 := strconv.ParseFloat(got, 64)
+	w, err2 := strconv.ParseFloat(want, 64)
+	return err1 == nil && err2 == nil && g == w
+}
@@ -0,0 +1,97 @@
+package monitor
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []jsonPathSegment
+		wantErr bool
+	}{
+		{path: "$.status", want: []jsonPathSegment{{key: "status"}}},
+		{path: "status", want: []jsonPathSegment{{key: "status"}}},
+		{path: "db.status", want: []jsonPathSegment{{key: "db"}, {key: "status"}}},
+		{path: "$.checks[0].name", want: []jsonPathSegment{{key: "checks"}, {index: 0, isIndex: true}, {key: "name"}}},
+		{path: "$[1]", want: []jsonPathSegment{{index: 1, isIndex: true}}},
+		{path: `$["a.b"]`, want: []jsonPathSegment{{key: "a.b"}}},
+		{path: "$['x'][2]", want: []jsonPathSegment{{key: "x"}, {index: 2, isIndex: true}}},
+		{path: "$", want: nil},
+		{path: "$.a[0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseJSONPath(tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseJSONPath(%q) = %v, want error", tt.path, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseJSONPath(%q): %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseJSONPath(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEvalJSONAssertions(t *testing.T) {
+	body := []byte(`{
+		"status": "ok",
+		"version": "1.0",
+		"uptime": 12.5,
+		"healthy": true,
+		"error": null,
+		"checks": [{"name": "db", "ms": 3}, {"name": "cache", "ms": 40}]
+	}`)
+
+	tests := []struct {
+		name   string
+		assert JSONAssertion
+		pass   bool
+	}{
+		{name: "equals string", assert: JSONAssertion{Path: "$.status", Op: JSONOpEquals, Value: "ok"}, pass: true},
+		{name: "equals mismatch", assert: JSONAssertion{Path: "$.status", Op: JSONOpEquals, Value: "down"}},
+		{name: "equals numeric", assert: JSONAssertion{Path: "$.uptime", Op: JSONOpEquals, Value: "12.50"}, pass: true},
+		{name: "equals numeric string", assert: JSONAssertion{Path: "$.version", Op: JSONOpEquals, Value: "1"}, pass: true},
+		{name: "equals bool", assert: JSONAssertion{Path: "$.healthy", Op: JSONOpEquals, Value: "true"}, pass: true},
+		{name: "equals null", assert: JSONAssertion{Path: "$.error", Op: JSONOpEquals, Value: "null"}, pass: true},
+		{name: "not equals", assert: JSONAssertion{Path: "$.status", Op: JSONOpNotEquals, Value: "down"}, pass: true},
+		{name: "not equals same", assert: JSONAssertion{Path: "$.status", Op: JSONOpNotEquals, Value: "ok"}},
+		{name: "exists", assert: JSONAssertion{Path: "$.checks[1].name", Op: JSONOpExists}, pass: true},
+		{name: "exists null", assert: JSONAssertion{Path: "$.error", Op: JSONOpExists}, pass: true},
+		{name: "missing key", assert: JSONAssertion{Path: "$.nope", Op: JSONOpExists}},
+		{name: "index out of range", assert: JSONAssertion{Path: "$.checks[2]", Op: JSONOpExists}},
+		{name: "index on object", assert: JSONAssertion{Path: "$.status[0]", Op: JSONOpExists}},
+		{name: "missing path with value op", assert: JSONAssertion{Path: "$.nope", Op: JSONOpEquals, Value: "x"}},
+		{name: "regex", assert: JSONAssertion{Path: "$.checks[0].name", Op: JSONOpRegex, Pattern: regexp.MustCompile(`^d`)}, pass: true},
+		{name: "regex mismatch", assert: JSONAssertion{Path: "$.checks[1].name", Op: JSONOpRegex, Pattern: regexp.MustCompile(`^d`)}},
+		{name: "gt", assert: JSONAssertion{Path: "$.uptime", Op: JSONOpGT, Value: "12"}, pass: true},
+		{name: "gt equal", assert: JSONAssertion{Path: "$.uptime", Op: JSONOpGT, Value: "12.5"}},
+		{name: "gte equal", assert: JSONAssertion{Path: "$.uptime", Op: JSONOpGTE, Value: "12.5"}, pass: true},
+		{name: "lt", assert: JSONAssertion{Path: "$.checks[1].ms", Op: JSONOpLT, Value: "50"}, pass: true},
+		{name: "lte exceeded", assert: JSONAssertion{Path: "$.checks[1].ms", Op: JSONOpLTE, Value: "39"}},
+		{name: "compare non-number", assert: JSONAssertion{Path: "$.status", Op: JSONOpGT, Value: "1"}},
+		{name: "unknown op", assert: JSONAssertion{Path: "$.status", Op: "contains", Value: "o"}},
+	}
+
+	for _, tt := range tests {
+		msg := evalJSONAssertions(body, []JSONAssertion{tt.assert})
+		if got := msg == ""; got != tt.pass {
+			t.Errorf("%s: pass = %v, want %v (%q)", tt.name, got, tt.pass, msg)
+		}
+	}
+}
+
+func TestEvalJSONAssertionsInvalidBody(t *testing.T) {
+	msg := evalJSONAssertions([]byte("<html>"), []JSONAssertion{{Path: "$.status", Op: JSONOpExists}})
+	if msg == "" {
+		t.Fatal("want a failure for a non-JSON body")
+	}
+}
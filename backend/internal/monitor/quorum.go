@@ -0,0 +1,282 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// QuorumProbe is the incidents.probe value of incidents decided by a quorum;
+// the probes that saw the target DOWN are kept in incidents.probes.
+const QuorumProbe = "quorum"
+
+// quorumState is the cross-probe UP/DOWN decision for a target with
+// AlertPolicy.Quorum > 1. Per-probe States keep their own confirmed status;
+// only this decision opens and closes incidents.
+type quorumState struct {
+	URL      string
+	Down     bool
+	Probes   []string // every probe that saw the target DOWN during the current outage
+	CausedBy string   // parent target whose outage explains this one
+
+	// Starved is set while fewer probes than the quorum have reported within
+	// the window: the last decision (UP or DOWN, DEGRADED or not) stands
+	// until more report, since too few probes can neither confirm an outage
+	// nor a recovery.
+	Starved bool
+
+	// DEGRADED by quorum (see evaluateQuorumDegraded).
+	Degraded bool
+
+	// Per-target counterparts of State.CertWarnedFor and State.LastContent, so
+	// one expiring certificate or page edit is reported once, not per probe.
+	// Stored under probe "quorum" in cert_warnings and content_snapshots.
+	CertWarnedFor time.Time
+	LastContent   *ContentSnapshot
+}
+
+// evaluateQuorum re-decides res's target from the per-probe States that
+// reported within the policy's QuorumWindow, and returns a transition event
+// when the decision flips. Probes in maintenance don't count.
+func evaluateQuorum(states map[stateKey]*State, qs *quorumState, res CheckResult, now time.Time) (Event, bool) {
+	p := res.Alert
+	qs.URL = res.URL
+
+	var (
+		fresh   int
+		failing []string
+		reason  string
+	)
+	for k, st := range states {
+		if k.Target != res.TargetName || st.Maintenance != nil || now.Sub(st.LastChecked) > p.QuorumWindow {
+			continue
+		}
+		fresh++
+		if !st.LastUp {
+			failing = append(failing, k.Probe)
+			if reason == "" || k.Probe == res.Probe {
+				reason = st.LastError
+			}
+		}
+	}
+	slices.Sort(failing)
+
+	if starved := fresh < p.Quorum; starved != qs.Starved {
+		qs.Starved = starved
+		if starved {
+			last := StatusUp
+			if qs.Down {
+				last = StatusDown
+			}
+			log.Printf("[WARN] quorum: %s has %d probes reporting within %s, fewer than its quorum of %d; keeping it %s",
+				res.TargetName, fresh, p.QuorumWindow, p.Quorum, last)
+		} else {
+			log.Printf("quorum: %s has %d probes reporting again (quorum %d)", res.TargetName, fresh, p.Quorum)
+		}
+	}
+
+	if qs.Down {
+		for _, probe := range failing {
+			if !slices.Contains(qs.Probes, probe) {
+				qs.Probes = append(qs.Probes, probe)
+			}
+		}
+		slices.Sort(qs.Probes)
+	}
+	if qs.Starved {
+		return Event{}, false
+	}
+
+	down := len(failing) >= p.Quorum
+	if down == qs.Down {
+		return Event{}, false
+	}
+	qs.Down = down
+
+	ev := Event{
+		Kind:       EventTransition,
+		TargetName: res.TargetName,
+		URL:        res.URL,
+		Probe:      QuorumProbe,
+		At:         res.At,
+		StatusCode: res.StatusCode,
+		Latency:    res.Latency,
+	}
+	if down {
+		qs.Probes = failing
+		ev.From, ev.To = true, false
+		ev.FromStatus, ev.ToStatus = StatusUp, StatusDown
+		ev.Reason = fmt.Sprintf("%d of %d probes DOWN", len(failing), fresh)
+		if reason != "" {
+			ev.Reason += ": " + reason
+		}
+	} else {
+		ev.From, ev.To = false, true
+		ev.FromStatus, ev.ToStatus = StatusDown, StatusUp
+		ev.Reason = fmt.Sprintf("%d of %d probes UP", fresh-len(failing), fresh)
+	}
+	ev.Probes = slices.Clone(qs.Probes)
+	if !down {
+		qs.Probes = nil
+	}
+	return ev, true
+}
+
+// evaluateQuorumDegraded is evaluateQuorum for DEGRADED: the target is
+// DEGRADED once at least Quorum probes reporting within the window are, and
+// while it is not DOWN by quorum. It returns an event when that flips.
+func evaluateQuorumDegraded(states map[stateKey]*State, qs *quorumState, res CheckResult, now time.Time) (Event, bool) {
+	p := res.Alert
+
+	var (
+		fresh    int
+		degraded []string
+	)
+	for k, st := range states {
+		if k.Target != res.TargetName || st.Maintenance != nil || now.Sub(st.LastChecked) > p.QuorumWindow {
+			continue
+		}
+		fresh++
+		if st.LastStatus == StatusDegraded {
+			degraded = append(degraded, k.Probe)
+		}
+	}
+	slices.Sort(degraded)
+	if fresh < p.Quorum {
+		return Event{}, false // starved: see quorumState.Starved
+	}
+
+	isDegraded := !qs.Down && len(degraded) >= p.Quorum
+	if isDegraded == qs.Degraded {
+		return Event{}, false
+	}
+	qs.Degraded = isDegraded
+
+	ev := Event{
+		Kind:       EventDegraded,
+		TargetName: res.TargetName,
+		URL:        res.URL,
+		Probe:      QuorumProbe,
+		Probes:     degraded,
+		From:       true,
+		To:         true,
+		At:         res.At,
+		StatusCode: res.StatusCode,
+		FromStatus: StatusUp,
+		ToStatus:   StatusDegraded,
+		Latency:    res.Latency,
+		Reason:     fmt.Sprintf("%d of %d probes DEGRADED", len(degraded), fresh),
+	}
+	if !isDegraded {
+		ev.Kind = EventDegradedRecovered
+		ev.FromStatus, ev.ToStatus = StatusDegraded, StatusUp
+		if qs.Down {
+			ev.To, ev.ToStatus, ev.Reason = false, StatusDown, "DOWN by quorum"
+		}
+	}
+	return ev, true
+}
+
+// parentDown reports whether parent counts as DOWN for a child whose failure
+// was seen by probes: by the parent's own quorum when it has one, otherwise
+// when the parent is DOWN on any of those probes.
+func parentDown(states map[stateKey]*State, quorums map[string]*quorumState, parent string, probes []string) bool {
+	if qs := quorums[parent]; qs != nil {
+		return qs.Down
+	}
+	return slices.ContainsFunc(probes, func(probe string) bool {
+		ps := states[stateKey{Target: parent, Probe: probe}]
+		return ps != nil && ps.TotalChecks > 0 && !ps.LastUp
+	})
+}
+
+// quorumDownParent returns the first of parents that is DOWN (see parentDown), or "".
+func quorumDownParent(states map[stateKey]*State, quorums map[string]*quorumState, parents, probes []string) string {
+	for _, p := range parents {
+		if parentDown(states, quorums, p, probes) {
+			return p
+		}
+	}
+	return ""
+}
+
+// releaseQuorumChildren is called when parent recovers (on any probe or by
+// quorum). Quorum targets whose outage was attributed to it and that are still
+// DOWN get their own (notified) DOWN event once the parent no longer counts
+// as DOWN for them.
+func releaseQuorumChildren(states map[stateKey]*State, quorums map[string]*quorumState, parent string, at time.Time) []Event {
+	var events []Event
+	for name, qs := range quorums {
+		if qs.CausedBy != parent || parentDown(states, quorums, parent, qs.Probes) {
+			continue
+		}
+		qs.CausedBy = ""
+		if !qs.Down {
+			continue
+		}
+		events = append(events, Event{
+			Kind:       EventTransition,
+			TargetName: name,
+			URL:        qs.URL,
+			Probe:      QuorumProbe,
+			Probes:     slices.Clone(qs.Probes),
+			From:       true,
+			To:         false,
+			At:         at,
+			Reason:     fmt.Sprintf("still down after %s recovered", parent),
+			FromStatus: StatusUp,
+			ToStatus:   StatusDown,
+		})
+	}
+	return events
+}
+
+// loadQuorumState restores open quorum incidents and the per-target
+// certificate warning and content snapshot, so a restart neither re-notifies
+// an ongoing outage nor misses its recovery.
+func loadQuorumState(ctx context.Context, db *pgxpool.Pool, target string) (*quorumState, error) {
+	qs := &quorumState{}
+	if db == nil {
+		return qs, nil
+	}
+
+	var causedBy *string
+	err := db.QueryRow(ctx, `
+		SELECT COALESCE(probes, '{}'), caused_by
+		  FROM incidents
+		 WHERE target_name = $1 AND probe = $2 AND kind = $3 AND ended_at IS NULL`,
+		target, QuorumProbe, IncidentDown,
+	).Scan(&qs.Probes, &causedBy)
+	switch {
+	case err == nil:
+		qs.Down = true
+		if causedBy != nil {
+			qs.CausedBy = *causedBy
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return qs, err
+	}
+
+	if err := db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM incidents
+			 WHERE target_name = $1 AND probe = $2 AND kind = $3 AND ended_at IS NULL)`,
+		target, QuorumProbe, IncidentDegraded,
+	).Scan(&qs.Degraded); err != nil {
+		return qs, err
+	}
+
+	if qs.CertWarnedFor, err = loadCertWarning(ctx, db, target, QuorumProbe); err != nil {
+		return qs, err
+	}
+	if qs.LastContent, err = loadContentSnapshot(ctx, db, target, QuorumProbe); err != nil {
+		return qs, err
+	}
+	return qs, nil
+}
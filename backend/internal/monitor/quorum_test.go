@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"cy-platforms-status-monitor/internal/maintenance"
+)
+
+// probeStates builds per-probe States for target "t" from "probe=status" pairs
+// (U, D, G for DEGRADED, M for DOWN in maintenance, S for DOWN but stale,
+// X for DEGRADED but stale).
+func probeStates(now time.Time, window time.Duration, probes map[string]byte) map[stateKey]*State {
+	states := make(map[stateKey]*State, len(probes))
+	for probe, s := range probes {
+		st := &State{Name: "t", Probe: probe, LastChecked: now, LastUp: true, LastStatus: StatusUp}
+		switch s {
+		case 'D':
+			st.LastUp, st.LastStatus, st.LastError = false, StatusDown, "timeout"
+		case 'G':
+			st.LastStatus = StatusDegraded
+		case 'M':
+			st.LastUp, st.LastStatus = false, StatusDown
+			st.Maintenance = &maintenance.Active{}
+		case 'S':
+			st.LastUp, st.LastStatus = false, StatusDown
+			st.LastChecked = now.Add(-2 * window)
+		case 'X':
+			st.LastStatus = StatusDegraded
+			st.LastChecked = now.Add(-2 * window)
+		}
+		states[stateKey{Target: "t", Probe: probe}] = st
+	}
+	return states
+}
+
+func TestEvaluateQuorum(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	window := 5 * time.Minute
+
+	tests := []struct {
+		name       string
+		quorum     int
+		probes     map[string]byte
+		wasDown    bool
+		wantEvent  bool
+		wantDown   bool
+		wantProbes []string
+	}{
+		{name: "one of three down", quorum: 2, probes: map[string]byte{"a": 'D', "b": 'U', "c": 'U'}},
+		{name: "two of three down", quorum: 2, probes: map[string]byte{"a": 'D', "b": 'D', "c": 'U'},
+			wantEvent: true, wantDown: true, wantProbes: []string{"a", "b"}},
+		{name: "still down", quorum: 2, wasDown: true, probes: map[string]byte{"a": 'D', "b": 'D', "c": 'D'}, wantDown: true},
+		{name: "recovers", quorum: 2, wasDown: true, probes: map[string]byte{"a": 'D', "b": 'U', "c": 'U'},
+			wantEvent: true, wantProbes: []string{"a", "b"}},
+		{name: "maintenance doesn't count", quorum: 2, probes: map[string]byte{"a": 'D', "b": 'M', "c": 'U'}},
+		{name: "stale doesn't count", quorum: 2, probes: map[string]byte{"a": 'D', "b": 'S', "c": 'U'}},
+		{name: "degraded is up", quorum: 2, probes: map[string]byte{"a": 'G', "b": 'G', "c": 'U'}},
+	}
+
+	for _, tt := range tests {
+		states := probeStates(now, window, tt.probes)
+		qs := &quorumState{Down: tt.wasDown}
+		if tt.wasDown {
+			qs.Probes = []string{"b"}
+		}
+		res := CheckResult{TargetName: "t", Probe: "a", At: now, Alert: AlertPolicy{Quorum: tt.quorum, QuorumWindow: window}}
+
+		ev, ok := evaluateQuorum(states, qs, res, now)
+		if ok != tt.wantEvent {
+			t.Errorf("%s: event = %v, want %v", tt.name, ok, tt.wantEvent)
+		}
+		if qs.Down != tt.wantDown {
+			t.Errorf("%s: down = %v, want %v", tt.name, qs.Down, tt.wantDown)
+		}
+		if !ok {
+			continue
+		}
+		if ev.Probe != QuorumProbe || ev.To == tt.wantDown {
+			t.Errorf("%s: event probe %q to %v, want %q to %v", tt.name, ev.Probe, ev.To, QuorumProbe, !tt.wantDown)
+		}
+		if !reflect.DeepEqual(ev.Probes, tt.wantProbes) {
+			t.Errorf("%s: event probes %v, want %v", tt.name, ev.Probes, tt.wantProbes)
+		}
+	}
+}
+
+func TestEvaluateQuorumStarved(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	window := 5 * time.Minute
+	states := probeStates(now, window, map[string]byte{"a": 'D', "b": 'S'})
+	qs := &quorumState{}
+	res := CheckResult{TargetName: "t", Probe: "a", At: now, Alert: AlertPolicy{Quorum: 2, QuorumWindow: window}}
+
+	if _, ok := evaluateQuorum(states, qs, res, now); ok || qs.Down {
+		t.Error("one fresh probe must not reach a quorum of 2")
+	}
+	if !qs.Starved {
+		t.Error("want Starved with fewer fresh probes than the quorum")
+	}
+
+	// DOWN by quorum, then b goes silent: a alone can't confirm a recovery.
+	qs = &quorumState{Down: true, Probes: []string{"a", "b"}}
+	if _, ok := evaluateQuorum(states, qs, res, now); ok || !qs.Down {
+		t.Error("a starved quorum must keep the target DOWN")
+	}
+
+	// Same for DEGRADED.
+	states = probeStates(now, window, map[string]byte{"a": 'U', "b": 'X'})
+	qs = &quorumState{Degraded: true}
+	if _, ok := evaluateQuorumDegraded(states, qs, res, now); ok || !qs.Degraded {
+		t.Error("a starved quorum must keep the target DEGRADED")
+	}
+}
+
+func TestEvaluateQuorumDegraded(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	window := 5 * time.Minute
+
+	tests := []struct {
+		name         string
+		probes       map[string]byte
+		wasDegraded  bool
+		down         bool
+		wantKind     string // "" for no event
+		wantDegraded bool
+	}{
+		{name: "one of three degraded", probes: map[string]byte{"a": 'G', "b": 'U', "c": 'U'}},
+		{name: "two of three degraded", probes: map[string]byte{"a": 'G', "b": 'G', "c": 'U'},
+			wantKind: EventDegraded, wantDegraded: true},
+		{name: "still degraded", wasDegraded: true, probes: map[string]byte{"a": 'G', "b": 'G', "c": 'G'}, wantDegraded: true},
+		{name: "recovers", wasDegraded: true, probes: map[string]byte{"a": 'G', "b": 'U', "c": 'U'},
+			wantKind: EventDegradedRecovered},
+		{name: "down by quorum ends degraded", wasDegraded: true, down: true, probes: map[string]byte{"a": 'G', "b": 'G', "c": 'D'},
+			wantKind: EventDegradedRecovered},
+		{name: "stale doesn't count", probes: map[string]byte{"a": 'G', "b": 'X', "c": 'U'}},
+	}
+
+	for _, tt := range tests {
+		states := probeStates(now, window, tt.probes)
+		qs := &quorumState{Degraded: tt.wasDegraded, Down: tt.down}
+		res := CheckResult{TargetName: "t", Probe: "a", At: now, Alert: AlertPolicy{Quorum: 2, QuorumWindow: window}}
+
+		ev, ok := evaluateQuorumDegraded(states, qs, res, now)
+		got := ""
+		if ok {
+			got = ev.Kind
+		}
+		if got != tt.wantKind {
+			t.Errorf("%s: event %q, want %q", tt.name, got, tt.wantKind)
+		}
+		if qs.Degraded != tt.wantDegraded {
+			t.Errorf("%s: degraded = %v, want %v", tt.name, qs.Degraded, tt.wantDegraded)
+		}
+		if ok && ev.Probe != QuorumProbe {
+			t.Errorf("%s: event probe %q, want %q", tt.name, ev.Probe, QuorumProbe)
+		}
+	}
+}
@@ -1,52 +1,198 @@
 package monitor
 
 import (
+	"container/heap"
 	"context"
 	"log"
 	"math/rand"
+	"sync"
 	"time"
 )
 
 // jitterFraction controls how much randomness we add to the schedule. 0.2 = ±20%.
 const jitterFraction = 0.2
 
+// lagReportEvery is how often the scheduler logs delays and rotates MaxLag.
+const lagReportEvery = time.Minute
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-// StartSchedulers starts one goroutine per enabled target.
-// Each scheduler ticks on target.Interval and tries to enqueue a CheckJob.
+// Scheduler runs checks for a set of targets from a min-heap of next-run
+// times. The target set can be replaced at runtime (see Update).
+type Scheduler struct {
+	updates chan []Target
+	stats   schedulerStats
+}
+
+// StartScheduler starts a single goroutine that schedules every enabled
+// target from a min-heap of next-run times.
 //
-// MVP backpressure policy: if jobsCh is full, drop the job (do not block).
-func StartSchedulers(
+// - first checks are spread at random across each target's interval instead of all firing at startup.
+// - after a check is enqueued, the next one is due Interval (±jitter) later.
+// - backpressure: when jobsCh is full the scheduler waits for room, so checks
+// run late rather than being dropped. Lateness is reported as lag (see Status).
+// Updates are still applied while it waits.
+func StartScheduler(
 	ctx context.Context,
 	targets []Target,
 	jobsCh chan<- CheckJob,
-) {
-	for _, t := range targets {
-		tt := t // capture loop var
+) *Scheduler {
+	s := &Scheduler{updates: make(chan []Target)}
+	h, _ := reschedule(nil, targets, time.Now())
+	go s.run(ctx, h, jobsCh)
+	return s
+}
 
-		if !tt.Enabled {
-			continue
+// Update replaces the scheduled targets. Targets that are unchanged keep their
+// next run time; see reschedule for the rest. It blocks until the scheduler
+// loop picks the update up, which it does even while waiting for room in jobsCh.
+func (s *Scheduler) Update(ctx context.Context, targets []Target) {
+	select {
+	case s.updates <- targets:
+	case <-ctx.Done():
+	}
+}
+
+// run dispatches due entries one at a time, going back through the select
+// between them, so updates and cancellation are never stuck behind a full
+// jobsCh.
+func (s *Scheduler) run(ctx context.Context, h schedHeap, jobsCh chan<- CheckJob) {
+	s.stats.setTargets(len(h))
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	report := time.NewTicker(lagReportEvery)
+	defer report.Stop()
+
+	var waiting *schedEntry // due entry that found jobsCh full
+	for {
+		var (
+			due  *schedEntry
+			send chan<- CheckJob // nil (never ready) unless an entry waits for room
+			wake <-chan time.Time
+		)
+		switch {
+		case len(h) == 0:
+			timer.Stop()
+		case h[0].next.After(time.Now()):
+			timer.Reset(time.Until(h[0].next))
+			wake = timer.C
+		default:
+			due = h[0]
+			select {
+			case jobsCh <- checkJob(due):
+				s.dispatched(h, due, due == waiting)
+				waiting = nil
+				continue
+			default:
+			}
+			waiting, send = due, jobsCh
+		}
+
+		var job CheckJob
+		if due != nil {
+			job = checkJob(due)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-report.C:
+			s.stats.rotate()
+		case targets := <-s.updates:
+			var d schedDiff
+			h, d = reschedule(h, targets, time.Now())
+			s.stats.setTargets(len(h))
+			log.Printf("scheduler: %d targets (%d added, %d updated, %d removed)", len(h), d.Added, d.Updated, d.Removed)
+		case <-wake:
+		case send <- job:
+			s.dispatched(h, due, true)
+			waiting = nil
 		}
+	}
+}
+
+// dispatched records that e (the heap's root) was enqueued and schedules its next run.
+func (s *Scheduler) dispatched(h schedHeap, e *schedEntry, delayed bool) {
+	s.stats.record(time.Since(e.next), delayed)
+	e.next = time.Now().Add(jitteredInterval(e.target.Interval))
+	heap.Fix(&h, 0)
+}
+
+// Status returns the scheduler's current counters.
+func (s *Scheduler) Status() SchedulerStats {
+	s.stats.mu.Lock()
+	defer s.stats.mu.Unlock()
+	return s.stats.s
+}
 
-		go func(target Target) {
-			// Send an immediate first check
-			enqueueJob(ctx, jobsCh, target)
-
-			for {
-				delay := jitteredInterval(target.Interval)
-				timer := time.NewTimer(delay)
-				select {
-				case <-ctx.Done():
-					timer.Stop()
-					return
-				case <-timer.C:
-					enqueueJob(ctx, jobsCh, target)
-				}
+// schedDiff counts what a reschedule changed.
+type schedDiff struct {
+	Added, Updated, Removed int
+}
+
+// reschedule merges targets into h and returns the new heap. Unchanged
+// targets keep their slot; changed ones keep it too unless their new interval
+// makes them due sooner; new ones get a random first run within their
+// interval; removed and disabled ones are dropped.
+func reschedule(h schedHeap, targets []Target, now time.Time) (schedHeap, schedDiff) {
+	old := make(map[string]*schedEntry, len(h))
+	for _, e := range h {
+		old[e.target.Name] = e
+	}
+
+	var d schedDiff
+	next := make(schedHeap, 0, len(targets))
+	for _, t := range targets {
+		if !t.Enabled {
+			continue
+		}
+		e, ok := old[t.Name]
+		if !ok {
+			d.Added++
+			next = append(next, &schedEntry{target: t, next: now.Add(initialOffset(t.Interval))})
+			continue
+		}
+		delete(old, t.Name)
+		if !sameTarget(e.target, t) {
+			d.Updated++
+			e.target = t
+			if due := now.Add(jitteredInterval(t.Interval)); due.Before(e.next) {
+				e.next = due
 			}
-		}(tt)
+		}
+		next = append(next, e)
+	}
+	d.Removed = len(old)
+
+	heap.Init(&next)
+	return next, d
+}
+
+// sameTarget reports whether a and b were built from the same configuration.
+// Targets without a ConfigHash are always taken as changed.
+func sameTarget(a, b Target) bool {
+	return a.ConfigHash != "" && a.ConfigHash == b.ConfigHash
+}
+
+// checkJob is the job for e's next run.
+func checkJob(e *schedEntry) CheckJob {
+	return CheckJob{
+		Target:      e.target,
+		ScheduledAt: e.next,
+		Attempt:     1,
+	}
+}
+
+// initialOffset picks when a target first runs: a random point within its
+// interval, so thousands of targets don't all fire at startup.
+func initialOffset(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
 	}
+	return time.Duration(rand.Int63n(int64(interval)))
 }
 
 // jitteredInterval returns the base interval plus a random jitter in ±jitterFraction.
@@ -64,19 +210,73 @@ func jitteredInterval(interval time.Duration) time.Duration {
 	return delay
 }
 
-func enqueueJob(ctx context.Context, jobsCh chan<- CheckJob, target Target) {
-	job := CheckJob{
-		Target:      target,
-		ScheduledAt: time.Now(),
-		Attempt:     1,
+// schedEntry is one target's slot in the scheduler heap.
+type schedEntry struct {
+	target Target
+	next   time.Time // when the next check is due
+}
+
+// schedHeap orders entries by next run time (earliest first).
+type schedHeap []*schedEntry
+
+func (h schedHeap) Len() int           { return len(h) }
+func (h schedHeap) Less(i, j int) bool { return h[i].next.Before(h[j].next) }
+func (h schedHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *schedHeap) Push(x any) { *h = append(*h, x.(*schedEntry)) }
+
+func (h *schedHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// SchedulerStats reports how closely checks run to their due time.
+type SchedulerStats struct {
+	Targets    int           // enabled targets being scheduled
+	Dispatched uint64        // jobs enqueued since start
+	Delayed    uint64        // jobs that had to wait for room in jobsCh
+	LastLag    time.Duration // due time to enqueue, for the latest job
+	MaxLag     time.Duration // worst lag over the last one to two minutes
+}
+
+// schedulerStats is shared between a scheduler goroutine and API readers.
+type schedulerStats struct {
+	mu sync.Mutex
+	s  SchedulerStats
+
+	curMax, prevMax time.Duration // MaxLag of the current and previous report period
+	curDelayed      uint64
+}
+
+func (st *schedulerStats) setTargets(n int) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.s.Targets = n
+}
+
+func (st *schedulerStats) record(lag time.Duration, delayed bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.s.Dispatched++
+	st.s.LastLag = lag
+	st.curMax = max(st.curMax, lag)
+	st.s.MaxLag = max(st.curMax, st.prevMax)
+	if delayed {
+		st.s.Delayed++
+		st.curDelayed++
 	}
+}
 
-	// Non-blocking send: drop if buffer full (MVP)
-	select {
-	case jobsCh <- job:
-	default:
-		log.Printf("[WARN] jobsCh full; dropping job for target=%s", target.Name)
-	case <-ctx.Done():
-		return
+// rotate starts a new report period, logging if jobs were held back in the last one.
+func (st *schedulerStats) rotate() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.curDelayed > 0 {
+		log.Printf("[WARN] scheduler: %d jobs waited for a full jobsCh in the last %s; max lag %s",
+			st.curDelayed, lagReportEvery, st.curMax.Round(time.Millisecond))
 	}
+	st.prevMax, st.curMax, st.curDelayed = st.curMax, 0, 0
+	st.s.MaxLag = st.prevMax
 }
@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestReschedule(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	target := func(name string, interval time.Duration) Target {
+		return Target{Name: name, URL: "https://" + name + ".example.com", Interval: interval, Enabled: true, ConfigHash: name + interval.String()}
+	}
+	entry := func(t Target, in time.Duration) *schedEntry { return &schedEntry{target: t, next: now.Add(in)} }
+
+	h := schedHeap{
+		entry(target("same", time.Minute), 40*time.Second),
+		entry(target("faster", time.Minute), 50*time.Second),
+		entry(target("slower", time.Minute), 30*time.Second),
+		entry(target("removed", time.Minute), 10*time.Second),
+		entry(target("disabled", time.Minute), 20*time.Second),
+	}
+	disabled := target("disabled", time.Minute)
+	disabled.Enabled = false
+
+	// A reload compiles patterns and selectors anew: only the config counts.
+	recompiled := target("same", time.Minute)
+	recompiled.Matches = []*regexp.Regexp{regexp.MustCompile("ok")}
+
+	h, d := reschedule(h, []Target{
+		recompiled,
+		target("faster", 10*time.Second),
+		target("slower", time.Hour),
+		target("added", 30*time.Second),
+		disabled,
+	}, now)
+
+	if d != (schedDiff{Added: 1, Updated: 2, Removed: 2}) {
+		t.Errorf("diff = %+v, want 1 added, 2 updated, 2 removed", d)
+	}
+	next := make(map[string]time.Duration, len(h))
+	for _, e := range h {
+		next[e.target.Name] = e.next.Sub(now)
+	}
+	if len(next) != 4 {
+		t.Fatalf("scheduled %v, want same, faster, slower and added", next)
+	}
+	if next["same"] != 40*time.Second {
+		t.Errorf("unchanged target moved to %s, want it to keep its slot", next["same"])
+	}
+	if next["slower"] != 30*time.Second {
+		t.Errorf("target with a longer interval moved to %s, want it to keep its slot", next["slower"])
+	}
+	if next["faster"] < 8*time.Second || next["faster"] > 12*time.Second {
+		t.Errorf("target with a shorter interval due in %s, want about 10s", next["faster"])
+	}
+	if next["added"] < 0 || next["added"] >= 30*time.Second {
+		t.Errorf("new target due in %s, want within its interval", next["added"])
+	}
+	if h[0].target.Name != "faster" && h[0].target.Name != "added" {
+		t.Errorf("heap root is %q, want the earliest entry", h[0].target.Name)
+	}
+}
+
+func TestSchedulerDispatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jobsCh := make(chan CheckJob, 100)
+	s := StartScheduler(ctx, []Target{
+		{Name: "a", Interval: 20 * time.Millisecond, Enabled: true},
+		{Name: "b", Interval: 30 * time.Millisecond, Enabled: true},
+		{Name: "off", Interval: 10 * time.Millisecond},
+	}, jobsCh)
+
+	counts := map[string]int{}
+	deadline := time.After(200 * time.Millisecond)
+	for done := false; !done; {
+		select {
+		case job := <-jobsCh:
+			counts[job.Target.Name]++
+			if job.Attempt != 1 || job.ScheduledAt.IsZero() {
+				t.Errorf("job %+v, want attempt 1 with a due time", job)
+			}
+		case <-deadline:
+			done = true
+		}
+	}
+
+	if counts["a"] < 3 || counts["b"] < 3 {
+		t.Errorf("dispatched %v, want several checks of a and b", counts)
+	}
+	if counts["off"] != 0 {
+		t.Errorf("disabled target dispatched %d times", counts["off"])
+	}
+	if st := s.Status(); st.Targets != 2 || st.Dispatched == 0 || st.Delayed != 0 {
+		t.Errorf("status = %+v, want 2 targets, jobs dispatched without delay", st)
+	}
+}
+
+func TestSchedulerBackpressure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Nobody reads jobsCh at first: the scheduler has to wait for room.
+	jobsCh := make(chan CheckJob)
+	s := StartScheduler(ctx, []Target{{Name: "a", Interval: 5 * time.Millisecond, Enabled: true}}, jobsCh)
+	time.Sleep(30 * time.Millisecond)
+
+	if job := <-jobsCh; job.Target.Name != "a" {
+		t.Fatalf("got a job for %q, want a", job.Target.Name)
+	}
+	// Stats are recorded right after the send completes.
+	st := s.Status()
+	for end := time.Now().Add(time.Second); st.Dispatched == 0 && time.Now().Before(end); st = s.Status() {
+		time.Sleep(time.Millisecond)
+	}
+	if st.Delayed != 1 || st.MaxLag < 20*time.Millisecond {
+		t.Errorf("status = %+v, want the waiting job counted as delayed with its lag", st)
+	}
+
+	// Updates are applied while the next job waits for room.
+	time.Sleep(20 * time.Millisecond)
+	updated := make(chan struct{})
+	go func() {
+		s.Update(ctx, []Target{{Name: "b", Interval: 5 * time.Millisecond, Enabled: true}})
+		close(updated)
+	}()
+	select {
+	case <-updated:
+	case <-time.After(time.Second):
+		t.Fatal("Update blocked behind a full jobsCh")
+	}
+
+	for range 3 {
+		select {
+		case job := <-jobsCh:
+			if job.Target.Name != "b" {
+				t.Fatalf("got a job for %q after it was removed", job.Target.Name)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no jobs after the update")
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StatusRange is an inclusive range of accepted HTTP status codes.
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+// statusAccepted reports whether code is in any of the ranges.
+// With no ranges configured, any 2xx or 3xx is accepted.
+func statusAccepted(code int, accepted []StatusRange) bool {
+	if len(accepted) == 0 {
+		return code >= 200 && code < 400
+	}
+	for _, r := range accepted {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// formatStatusRanges renders ranges for validation messages, e.g. "200, 204, 300-399".
+func formatStatusRanges(accepted []StatusRange) string {
+	if len(accepted) == 0 {
+		return "200-399"
+	}
+	parts := make([]string, 0, len(accepted))
+	for _, r := range accepted {
+		if r.Min == r.Max {
+			parts = append(parts, fmt.Sprintf("%d", r.Min))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", r.Min, r.Max))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Extraction sources for Step.Extract.
+const (
+	ExtractHeader = "header"
+	ExtractRegex  = "regex"
+	ExtractJSON   = "json"
+)
+
+// Step is one HTTP request in a "steps" target. Request carries the URL,
+// method, headers, body and assertions exactly like an "http" target; any
+// {{var}} placeholders are filled from values extracted by earlier steps.
+type Step struct {
+	Request Target
+	Extract []Extraction
+}
+
+// Extraction stores one value from a step's response into a variable.
+type Extraction struct {
+	Var     string
+	From    string         // header, regex or json
+	Key     string         // header name or JSON path
+	Pattern *regexp.Regexp // for regex: first capture group (or whole match)
+}
+
+// StepResult is the outcome of a single step.
+type StepResult struct {
+	Name       string
+	URL        string
+	Up         bool
+	StatusCode int
+	Latency    time.Duration
+	Error      string
+	Validation string
+}
+
+// StepVarRef matches {{name}} placeholders in step requests; config checks
+// with it that every variable is extracted by an earlier step.
+var StepVarRef = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// StepsChecker is the Checker for "steps" targets: a transaction such as
+// "load login page, extract CSRF token, POST credentials, assert dashboard".
+// Each check gets a fresh cookie jar shared by all of its steps, and the
+// target's Timeout bounds the whole transaction.
+type StepsChecker struct {
+	client *http.Client
+}
+
+// NewStepsChecker returns a steps checker that issues requests through client's transport.
+func NewStepsChecker(client *http.Client) *StepsChecker {
+	return &StepsChecker{client: client}
+}
+
+func (c *StepsChecker) Check(ctx context.Context, t Target) CheckResult {
+	start := time.Now()
+
+	res := CheckResult{
+		TargetName: t.Name,
+		URL:        t.URL,
+		At:         time.Now(),
+		Attempt:    1,
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		res.Error = fmt.Sprintf("cookie jar: %v", err)
+		res.Latency = time.Since(start)
+		return res
+	}
+	client := *c.client
+	client.Jar = jar
+
+	vars := make(map[string]string)
+
+	for i, step := range t.Steps {
+		prefix := fmt.Sprintf("step %d (%s): ", i+1, step.Request.Name)
+		req := withVars(step.Request, vars)
+		if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+			msg := fmt.Sprintf("url must start with http:// or https:// (got %q)", req.URL)
+			res.Steps = append(res.Steps, StepResult{Name: step.Request.Name, URL: req.URL, Validation: msg})
+			res.Validation = prefix + msg
+			res.Latency = time.Since(start)
+			return res
+		}
+		ex := &httpExchange{wantBody: needsBody(step.Extract)}
+
+		stepRes := checkHTTP(ctx, &client, req, ex)
+		res.Steps = append(res.Steps, StepResult{
+			Name:       step.Request.Name,
+			URL:        req.URL,
+			Up:         stepRes.Up,
+			StatusCode: stepRes.StatusCode,
+			Latency:    stepRes.Latency,
+			Error:      stepRes.Error,
+			Validation: stepRes.Validation,
+		})
+		res.StatusCode = stepRes.StatusCode
+		if stepRes.TLS != nil {
+			res.TLS = stepRes.TLS
+		}
+
+		if !stepRes.Up {
+			if stepRes.Error != "" {
+				res.Error = prefix + stepRes.Error
+			}
+			if stepRes.Validation != "" {
+				res.Validation = prefix + stepRes.Validation
+			}
+			res.Latency = time.Since(start)
+			return res
+		}
+
+		if msg := extractVars(step.Extract, ex, vars); msg != "" {
+			res.Validation = prefix + msg
+			res.Steps[len(res.Steps)-1].Up = false
+			res.Steps[len(res.Steps)-1].Validation = msg
+			res.Latency = time.Since(start)
+			return res
+		}
+	}
+
+	res.Up = true
+	res.Latency = time.Since(start)
+	return res
+}
+
+// withVars returns a copy of t with {{var}} placeholders substituted in the
+// URL, headers, body and bearer token. Values placed in the URL's query string
+// are query-escaped; everywhere else they are inserted as-is.
+func withVars(t Target, vars map[string]string) Target {
+	if len(vars) == 0 {
+		return t
+	}
+
+	subWith := func(s string, escape func(string) string) string {
+		return StepVarRef.ReplaceAllStringFunc(s, func(ref string) string {
+			if v, ok := vars[StepVarRef.FindStringSubmatch(ref)[1]]; ok {
+				return escape(v)
+			}
+			return ref
+		})
+	}
+	sub := func(s string) string {
+		return subWith(s, func(v string) string { return v })
+	}
+
+	if base, query, ok := strings.Cut(t.URL, "?"); ok {
+		t.URL = sub(base) + "?" + subWith(query, url.QueryEscape)
+	} else {
+		t.URL = sub(t.URL)
+	}
+	t.Body = sub(t.Body)
+	t.BearerToken = sub(t.BearerToken)
+	if len(t.Headers) > 0 {
+		headers := make(map[string]string, len(t.Headers))
+		for k, v := range t.Headers {
+			headers[k] = sub(v)
+		}
+		t.Headers = headers
+	}
+	return t
+}
+
+// needsBody reports whether any extraction reads the response body.
+func needsBody(extract []Extraction) bool {
+	for _, e := range extract {
+		if e.From != ExtractHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// extractVars applies every extraction to the step's response, storing results in vars.
+// Returns "" on success, otherwise which extraction found nothing.
+func extractVars(extract []Extraction, ex *httpExchange, vars map[string]string) string {
+	for _, e := range extract {
+		var (
+			val string
+			ok  bool
+		)
+
+		switch e.From {
+		case ExtractHeader:
+			val = ex.header.Get(e.Key)
+			ok = val != ""
+		case ExtractRegex:
+			if m := e.Pattern.FindStringSubmatch(string(ex.body)); m != nil {
+				val, ok = m[0], true
+				if len(m) > 1 {
+					val = m[1]
+				}
+			}
+		case ExtractJSON:
+			var doc any
+			dec := json.NewDecoder(strings.NewReader(string(ex.body)))
+			dec.UseNumber()
+			if err := dec.Decode(&doc); err == nil {
+				var got any
+				if got, ok, _ = lookupJSONPath(doc, e.Key); ok {
+					val = jsonValueText(got)
+				}
+			}
+		default:
+			return fmt.Sprintf("extract %s: unknown source %q", e.Var, e.From)
+		}
+
+		if !ok {
+			return fmt.Sprintf("extract %s: no match in %s", e.Var, e.From)
+		}
+		vars[e.Var] = val
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package monitor
+
+import "sync/atomic"
+
+// targetSet is the configured (enabled) targets as of the last PublishTargets.
+type targetSet struct {
+	gen    uint64
+	byName map[string]Target
+}
+
+var (
+	currentTargets atomic.Pointer[targetSet]
+	targetsGen     atomic.Uint64
+)
+
+// PublishTargets replaces the set of configured targets, at startup and after
+// each config reload. The Aggregator then drops state for targets that are no
+// longer there, and the ingest API only accepts results for enabled targets.
+func PublishTargets(targets []Target) {
+	ts := &targetSet{gen: targetsGen.Add(1), byName: make(map[string]Target, len(targets))}
+	for _, t := range targets {
+		if t.Enabled {
+			ts.byName[t.Name] = t
+		}
+	}
+	currentTargets.Store(ts)
+}
+
+// LookupTarget returns the enabled target called name.
+func LookupTarget(name string) (Target, bool) {
+	ts := currentTargets.Load()
+	if ts == nil {
+		return Target{}, false
+	}
+	t, ok := ts.byName[name]
+	return t, ok
+}
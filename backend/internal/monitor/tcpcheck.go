@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxBannerBytes caps how much we read while waiting for a service banner.
+const maxBannerBytes = 512
+
+// TCPChecker is the Checker for "tcp" targets (url: tcp://host:port).
+// A check is UP when the connection is established and, if configured,
+// the first line sent by the server starts with TCP.BannerPrefix.
+type TCPChecker struct {
+	dialer *net.Dialer
+}
+
+// NewTCPChecker returns a TCP checker. The per-job ctx bounds the dial and banner read.
+func NewTCPChecker() *TCPChecker {
+	return &TCPChecker{dialer: &net.Dialer{}}
+}
+
+func (c *TCPChecker) Check(ctx context.Context, t Target) CheckResult {
+	start := time.Now()
+
+	res := CheckResult{
+		TargetName: t.Name,
+		URL:        t.URL,
+		At:         time.Now(),
+		Attempt:    1,
+	}
+
+	addr, err := tcpAddress(t.URL)
+	if err != nil {
+		res.Error = err.Error()
+		res.Latency = time.Since(start)
+		return res
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", addr)
+	res.Latency = time.Since(start)
+	if err != nil {
+		res.Error = classifyHTTPError(err)
+		return res
+	}
+	defer conn.Close()
+
+	prefix := t.TCP.BannerPrefix
+	if prefix == "" {
+		res.Up = true
+		return res
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+	}
+
+	line, err := bufio.NewReader(io.LimitReader(conn, maxBannerBytes)).ReadString('\n')
+	if err != nil && line == "" {
+		res.Error = fmt.Sprintf("read banner: %v", classifyHTTPError(err))
+		return res
+	}
+
+	banner := strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(banner, prefix) {
+		res.Validation = fmt.Sprintf("banner mismatch: got %q want prefix %q", banner, prefix)
+		return res
+	}
+
+	res.Up = true
+	return res
+}
+
+// tcpAddress extracts host:port from a tcp:// URL.
+func tcpAddress(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("parse url: %v", err)
+	}
+	if _, _, err := net.SplitHostPort(u.Host); err != nil {
+		return "", fmt.Errorf("invalid address %q: %v", u.Host, err)
+	}
+	return u.Host, nil
+}
@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bannerServer accepts connections on a local port and greets each with banner.
+func bannerServer(t *testing.T, banner string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			if banner != "" {
+				conn.Write([]byte(banner))
+			}
+			time.Sleep(50 * time.Millisecond)
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// closedAddr returns a local address nothing listens on.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestTCPChecker(t *testing.T) {
+	silent := bannerServer(t, "")
+	smtp := bannerServer(t, "220 mail.example.com ESMTP\r\n")
+
+	tests := []struct {
+		name      string
+		url       string
+		banner    string
+		wantUp    bool
+		wantError string // substring of Error
+		wantValid string // substring of Validation
+	}{
+		{name: "connect only", url: "tcp://" + silent, wantUp: true},
+		{name: "banner matches", url: "tcp://" + smtp, banner: "220 ", wantUp: true},
+		{name: "banner mismatch", url: "tcp://" + smtp, banner: "SSH-", wantValid: "banner mismatch"},
+		{name: "no banner before timeout", url: "tcp://" + silent, banner: "220", wantError: "read banner"},
+		{name: "connection refused", url: "tcp://" + closedAddr(t), wantError: "connection refused"},
+		{name: "missing port", url: "tcp://127.0.0.1", wantError: "invalid address"},
+	}
+
+	c := NewTCPChecker()
+	for _, tt := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		res := c.Check(ctx, Target{Name: "t", URL: tt.url, TCP: TCPOptions{BannerPrefix: tt.banner}})
+		cancel()
+
+		if res.Up != tt.wantUp {
+			t.Errorf("%s: up = %v, want %v (error %q, validation %q)", tt.name, res.Up, tt.wantUp, res.Error, res.Validation)
+		}
+		if !strings.Contains(res.Error, tt.wantError) || (tt.wantError == "") != (res.Error == "") {
+			t.Errorf("%s: error %q, want %q", tt.name, res.Error, tt.wantError)
+		}
+		if !strings.Contains(res.Validation, tt.wantValid) || (tt.wantValid == "") != (res.Validation == "") {
+			t.Errorf("%s: validation %q, want %q", tt.name, res.Validation, tt.wantValid)
+		}
+	}
+}
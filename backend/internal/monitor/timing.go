@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing breaks an HTTP check's latency into phases. When a check follows
+// redirects, DNS/Connect/TLS/TTFB are summed across hops; Transfer covers the
+// final response body only.
+type Timing struct {
+	DNS      time.Duration // name resolution
+	Connect  time.Duration // TCP connect
+	TLS      time.Duration // TLS handshake
+	TTFB     time.Duration // request written -> first response byte (server think time)
+	Transfer time.Duration // first response byte -> body read
+}
+
+// traceRecorder collects httptrace callbacks. Callbacks may fire from the
+// transport's dial goroutines, so every access is locked.
+type traceRecorder struct {
+	mu sync.Mutex
+
+	timing       Timing
+	dnsStart     time.Time
+	connectStart map[string]time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	bodyDone     time.Time
+
+	// Connection of the first request (the target's own host, before any
+	// redirect) and its TLS state, if it was a TLS connection.
+	gotConn  bool
+	firstTLS *tls.ConnectionState
+}
+
+func newTraceRecorder() *traceRecorder {
+	return &traceRecorder{connectStart: make(map[string]time.Time)}
+}
+
+func (r *traceRecorder) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			r.mu.Lock()
+			r.dnsStart = time.Now()
+			r.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.mu.Lock()
+			if !r.dnsStart.IsZero() {
+				r.timing.DNS += time.Since(r.dnsStart)
+			}
+			r.mu.Unlock()
+		},
+		ConnectStart: func(network, addr string) {
+			r.mu.Lock()
+			r.connectStart[network+addr] = time.Now()
+			r.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			r.mu.Lock()
+			// Parallel dials (happy eyeballs) each report; count only the winner.
+			if start, ok := r.connectStart[network+addr]; ok && err == nil {
+				r.timing.Connect += time.Since(start)
+			}
+			r.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			r.mu.Lock()
+			r.tlsStart = time.Now()
+			r.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.mu.Lock()
+			if !r.tlsStart.IsZero() {
+				r.timing.TLS += time.Since(r.tlsStart)
+			}
+			r.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.mu.Lock()
+			if !r.gotConn {
+				r.gotConn = true
+				if tc, ok := info.Conn.(*tls.Conn); ok {
+					cs := tc.ConnectionState()
+					r.firstTLS = &cs
+				}
+			}
+			r.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			r.mu.Lock()
+			r.wroteRequest = time.Now()
+			r.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			r.mu.Lock()
+			r.firstByte = time.Now()
+			if !r.wroteRequest.IsZero() {
+				r.timing.TTFB += r.firstByte.Sub(r.wroteRequest)
+			}
+			r.mu.Unlock()
+		},
+	}
+}
+
+// requestedTLS returns the TLS state of the connection to the requested host.
+// A failed certificate verification never yields a connection, so in that case
+// the unverified chain is taken from err (only when no earlier hop connected,
+// i.e. the failure was on the requested host itself).
+func (r *traceRecorder) requestedTLS(err error) *tls.ConnectionState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.gotConn {
+		return r.firstTLS
+	}
+	var certErr *tls.CertificateVerificationError
+	if err != nil && errors.As(err, &certErr) {
+		return &tls.ConnectionState{PeerCertificates: certErr.UnverifiedCertificates}
+	}
+	return nil
+}
+
+// markBodyDone records that the final response body has been consumed.
+func (r *traceRecorder) markBodyDone() {
+	r.mu.Lock()
+	r.bodyDone = time.Now()
+	r.mu.Unlock()
+}
+
+// result returns the collected phases, or nil when no response was received
+// (DNS/connect failures, timeouts before the first byte): partial phases would
+// read as zeros in latency stats.
+func (r *traceRecorder) result() *Timing {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.firstByte.IsZero() {
+		return nil
+	}
+	t := r.timing
+	if !r.firstByte.IsZero() && r.bodyDone.After(r.firstByte) {
+		t.Transfer = r.bodyDone.Sub(r.firstByte)
+	}
+	return &t
+}
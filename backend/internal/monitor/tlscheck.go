@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+// defaultCertWarningDays is used when a target doesn't set TLS.ExpiryWarningDays.
+const defaultCertWarningDays = 14
+
+// TLSChecker is the Checker for "tls" targets (url: tls://host[:port], port defaults to 443).
+// It performs a handshake without trusting the connection, then verifies the
+// chain itself so certificate details are recorded even when the chain is broken.
+type TLSChecker struct{}
+
+// NewTLSChecker returns a TLS checker.
+func NewTLSChecker() *TLSChecker {
+	return &TLSChecker{}
+}
+
+func (c *TLSChecker) Check(ctx context.Context, t Target) CheckResult {
+	start := time.Now()
+
+	res := CheckResult{
+		TargetName: t.Name,
+		URL:        t.URL,
+		At:         time.Now(),
+		Attempt:    1,
+	}
+
+	host, addr, err := tlsAddress(t.URL)
+	if err != nil {
+		res.Error = err.Error()
+		res.Latency = time.Since(start)
+		return res
+	}
+
+	dialer := &tls.Dialer{Config: &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true, // verified below so we can still inspect bad chains
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	res.Latency = time.Since(start)
+	if err != nil {
+		res.Error = classifyHTTPError(err)
+		return res
+	}
+	defer conn.Close()
+
+	cs := conn.(*tls.Conn).ConnectionState()
+	res.TLS = inspectTLS(&cs, host, t.TLS.ExpiryWarningDays, time.Now())
+	if res.TLS == nil {
+		res.Validation = "tls: server sent no certificate"
+		return res
+	}
+	if !res.TLS.ChainValid {
+		res.Validation = res.TLS.ChainError
+		return res
+	}
+
+	res.Up = true
+	return res
+}
+
+// inspectTLS extracts leaf certificate details from a handshake and verifies the chain for host.
+// When the handshake was already verified by crypto/tls (VerifiedChains set) it is trusted as-is.
+func inspectTLS(cs *tls.ConnectionState, host string, warnDays int, now time.Time) *TLSInfo {
+	if cs == nil || len(cs.PeerCertificates) == 0 {
+		return nil
+	}
+	if warnDays <= 0 {
+		warnDays = defaultCertWarningDays
+	}
+
+	leaf := cs.PeerCertificates[0]
+	info := &TLSInfo{
+		NotAfter: leaf.NotAfter,
+		Issuer:   leaf.Issuer.CommonName,
+		Subject:  leaf.Subject.CommonName,
+		SANs:     append([]string(nil), leaf.DNSNames...),
+	}
+	if info.Issuer == "" {
+		info.Issuer = leaf.Issuer.String()
+	}
+
+	if len(cs.VerifiedChains) > 0 {
+		info.ChainValid = true
+	} else {
+		intermediates := x509.NewCertPool()
+		for _, c := range cs.PeerCertificates[1:] {
+			intermediates.AddCert(c)
+		}
+		_, err := leaf.Verify(x509.VerifyOptions{
+			DNSName:       host,
+			Intermediates: intermediates,
+			CurrentTime:   now,
+		})
+		info.ChainValid = err == nil
+		if err != nil {
+			info.ChainError = classifyTLSError(err)
+		}
+	}
+
+	info.Expiring = info.DaysToExpiry(now) <= warnDays
+	return info
+}
+
+// classifyTLSError maps x509 verification failures to short, stable reasons.
+// Returns "" if err is not a certificate error.
+func classifyTLSError(err error) string {
+	var (
+		invalid   x509.CertificateInvalidError
+		unknownCA x509.UnknownAuthorityError
+		hostname  x509.HostnameError
+	)
+	switch {
+	case errors.As(err, &invalid):
+		if invalid.Reason == x509.Expired {
+			return "tls: certificate expired or not yet valid"
+		}
+		return fmt.Sprintf("tls: invalid certificate: %v", invalid)
+	case errors.As(err, &unknownCA):
+		return "tls: certificate signed by unknown authority"
+	case errors.As(err, &hostname):
+		return fmt.Sprintf("tls: certificate not valid for %s", hostname.Host)
+	}
+	return ""
+}
+
+// tlsAddress returns the SNI host and dial address for a tls:// URL.
+func tlsAddress(raw string) (host, addr string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", fmt.Errorf("parse url: %v", err)
+	}
+	host = u.Hostname()
+	if host == "" {
+		return "", "", fmt.Errorf("invalid tls url %q", raw)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+	return host, net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// selfSigned returns a self-signed certificate for 127.0.0.1 and dnsNames.
+func selfSigned(t *testing.T, notAfter time.Time, dnsNames ...string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "status-test"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// tlsServer completes TLS handshakes with cert on a local port.
+func tlsServer(t *testing.T, cert tls.Certificate) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestTLSChecker(t *testing.T) {
+	now := time.Now()
+	untrusted := tlsServer(t, selfSigned(t, now.Add(90*24*time.Hour), "status.example.test"))
+	expired := tlsServer(t, selfSigned(t, now.Add(-24*time.Hour)))
+
+	tests := []struct {
+		name      string
+		url       string
+		wantError string // substring of Error
+		wantValid string // substring of Validation
+	}{
+		{name: "unknown authority", url: "tls://" + untrusted, wantValid: "signed by unknown authority"},
+		{name: "expired", url: "tls://" + expired, wantValid: "expired or not yet valid"},
+		{name: "wrong host", url: "tls://localhost:" + strings.Split(untrusted, ":")[1], wantValid: "not valid for localhost"},
+		{name: "connection refused", url: "tls://" + closedAddr(t), wantError: "connection refused"},
+		{name: "no host", url: "tls://", wantError: "invalid tls url"},
+	}
+
+	c := NewTLSChecker()
+	for _, tt := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		res := c.Check(ctx, Target{Name: "t", URL: tt.url})
+		cancel()
+
+		if res.Up {
+			t.Errorf("%s: want DOWN with an untrusted certificate", tt.name)
+		}
+		if !strings.Contains(res.Error, tt.wantError) || (tt.wantError == "") != (res.Error == "") {
+			t.Errorf("%s: error %q, want %q", tt.name, res.Error, tt.wantError)
+		}
+		if !strings.Contains(res.Validation, tt.wantValid) || (tt.wantValid == "") != (res.Validation == "") {
+			t.Errorf("%s: validation %q, want %q", tt.name, res.Validation, tt.wantValid)
+		}
+		if tt.wantValid != "" && (res.TLS == nil || res.TLS.ChainValid || res.TLS.ChainError != res.Validation) {
+			t.Errorf("%s: TLS = %+v, want details with the chain error", tt.name, res.TLS)
+		}
+	}
+}
+
+func TestInspectTLS(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	cert := selfSigned(t, now.Add(10*24*time.Hour+time.Hour), "status.example.test").Leaf
+
+	tests := []struct {
+		name         string
+		verified     bool
+		warnDays     int
+		wantValid    bool
+		wantExpiring bool
+	}{
+		{name: "verified by crypto/tls", verified: true, wantValid: true, wantExpiring: true},
+		{name: "unverified", wantExpiring: true},
+		{name: "outside warning window", verified: true, warnDays: 7, wantValid: true},
+		{name: "inside warning window", verified: true, warnDays: 10, wantValid: true, wantExpiring: true},
+	}
+
+	for _, tt := range tests {
+		cs := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		if tt.verified {
+			cs.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+		info := inspectTLS(cs, "status.example.test", tt.warnDays, now)
+		if info.ChainValid != tt.wantValid || info.Expiring != tt.wantExpiring {
+			t.Errorf("%s: valid %v expiring %v, want %v %v", tt.name, info.ChainValid, info.Expiring, tt.wantValid, tt.wantExpiring)
+		}
+		if info.Subject != "status-test" || info.DaysToExpiry(now) != 10 || len(info.SANs) != 1 {
+			t.Errorf("%s: details = %+v", tt.name, info)
+		}
+	}
+
+	if inspectTLS(&tls.ConnectionState{}, "status.example.test", 0, now) != nil {
+		t.Error("want nil without peer certificates")
+	}
+}
+
+func TestHTTPCheckRecordsTLS(t *testing.T) {
+	final := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer final.Close()
+
+	requested := httptest.NewUnstartedServer(http.RedirectHandler(final.URL, http.StatusFound))
+	requested.TLS = &tls.Config{Certificates: []tls.Certificate{selfSigned(t, time.Now().Add(30*24*time.Hour), "status.example.test")}}
+	requested.Config.ErrorLog = log.New(io.Discard, "", 0) // failed handshakes are expected
+	requested.StartTLS()
+	defer requested.Close()
+
+	// Verification fails against the system roots: details are still recorded.
+	res := CheckOnce(context.Background(), NewHTTPClient(HTTPClientConfig{Timeout: time.Second}), Target{Name: "t", URL: requested.URL})
+	if res.Up || res.TLS == nil || res.TLS.ChainValid || res.TLS.Subject != "status-test" {
+		t.Errorf("failed verification: up %v, TLS %+v; want the requested host's certificate", res.Up, res.TLS)
+	}
+
+	// After a redirect the certificate is still the requested host's.
+	insecure := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	res = CheckOnce(context.Background(), insecure, Target{Name: "t", URL: requested.URL, FollowRedirects: true, MaxRedirects: 5})
+	if !res.Up || res.TLS == nil || res.TLS.Subject != "status-test" {
+		t.Errorf("redirected: up %v, TLS %+v; want the requested host's certificate", res.Up, res.TLS)
+	}
+}
@@ -1,52 +1,250 @@
 package monitor
 
-import "time"
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"cy-platforms-status-monitor/internal/maintenance"
+)
 
 // Target describes what to check and how.
 type Target struct {
 	Name     string
+	Type     string // checker kind, e.g. "http" (see Registry)
 	URL      string
-	Method   string        // "GET" or "HEAD"
+	Method   string        // "GET", "HEAD", "POST", ...
 	Interval time.Duration // how often to schedule checks
 	Timeout  time.Duration // per-request timeout
 
-	ExpectedStatus int    // default 200
-	Contains       string // optional keyword check (GET only)
-	MaxBodyBytes   int64  // limit response read when doing Contains
+	ExpectedStatus []StatusRange     // accepted codes; empty means 200-399
+	Contains       string            // optional keyword check (not HEAD)
+	MaxBodyBytes   int64             // limit response read when doing Contains
+	JSONAssert     []JSONAssertion   // optional JSON body assertions (not HEAD)
+	ExpectHeaders  []HeaderAssertion // optional response header assertions
+
+	Headers     map[string]string // extra request headers ("Host" overrides the Host header)
+	Body        string            // request body (POST/PUT/PATCH)
+	BasicAuth   *BasicAuth        // optional HTTP basic auth
+	BearerToken string            // optional "Authorization: Bearer" token
+
+	FollowRedirects  bool   // when false, the first 3xx response is evaluated as-is
+	MaxRedirects     int    // hops allowed when following redirects
+	ExpectedFinalURL string // optional: URL the redirect chain must end at
+
+	DegradedLatency time.Duration // passing checks slower than this are DEGRADED (0 = off)
+
+	Alert AlertPolicy // when results become transitions and notifications
+
+	Retries    int           // extra attempts after a failed check, within the same slot
+	RetryDelay time.Duration // pause between attempts
+
+	Matches     []*regexp.Regexp // body must match every pattern
+	NotContains []string         // body must not contain any of these (e.g. maintenance notices)
+	NotMatches  []*regexp.Regexp // body must not match any pattern
+
+	WatchContent *ContentWatch // optional: alert when the page content changes (GET only)
+
+	TCP       TCPOptions       // used when Type == "tcp"
+	DNS       DNSOptions       // used when Type == "dns"
+	TLS       TLSOptions       // used by "tls" targets and https:// "http" targets
+	GRPC      GRPCOptions      // used when Type == "grpc"
+	WebSocket WebSocketOptions // used when Type == "websocket"
+
+	Steps []Step // used when Type == "steps"
 
 	Enabled bool
 	Tags    []string
+
+	// ConfigHash identifies the configuration the target was built from
+	// (see config.Target.Fingerprint). A reload reschedules a target only
+	// when it changes.
+	ConfigHash string
+}
+
+// BasicAuth holds HTTP basic auth credentials.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// hasBodyChecks reports whether the check needs to read the response body.
+func (t Target) hasBodyChecks() bool {
+	return strings.TrimSpace(t.Contains) != "" ||
+		len(t.Matches) > 0 ||
+		len(t.NotContains) > 0 ||
+		len(t.NotMatches) > 0 ||
+		len(t.JSONAssert) > 0 ||
+		t.WatchContent != nil
+}
+
+// AlertPolicy controls how the Aggregator turns results into transitions.
+type AlertPolicy struct {
+	DownAfter int // consecutive failures before the target is confirmed DOWN (default 1)
+	UpAfter   int // consecutive successes before it is confirmed UP again (default 1)
+
+	FlapThreshold int           // confirmed transitions within FlapWindow that mark the target FLAPPING (0 = off)
+	FlapWindow    time.Duration // rolling window for FlapThreshold
+
+	DependsOn []string // parent targets; while one is DOWN this target's alerts are suppressed
+
+	// Quorum > 1 makes UP/DOWN a cross-probe decision: the target is DOWN once
+	// that many probes, among those that reported within QuorumWindow, are
+	// DOWN. Per-probe flips then open no incidents of their own, which is why
+	// config rejects FlapThreshold together with a quorum. Agent results
+	// older than QuorumWindow are rejected on ingest.
+	Quorum       int
+	QuorumWindow time.Duration
+}
+
+// TCPOptions configures a "tcp" target.
+type TCPOptions struct {
+	BannerPrefix string // optional: first line sent by the server must start with this
+}
+
+// DNSOptions configures a "dns" target.
+type DNSOptions struct {
+	RecordType string   // A (default), AAAA, CNAME, MX or TXT
+	Resolver   string   // optional nameserver host[:port]; empty uses the system resolver
+	Expect     []string // values that must all be present in the answer
+	MinRecords int      // minimum number of records (default 1)
+}
+
+// TLSOptions configures certificate monitoring.
+type TLSOptions struct {
+	ExpiryWarningDays int // warn when the certificate expires within this many days (default 14)
+}
+
+// GRPCOptions configures a "grpc" target.
+type GRPCOptions struct {
+	Service            string // service name sent in HealthCheckRequest; empty = overall server health
+	TLS                bool   // dial with TLS instead of plaintext
+	InsecureSkipVerify bool   // with TLS: don't verify the server certificate
+}
+
+// WebSocketOptions configures a "websocket" target.
+type WebSocketOptions struct {
+	Send   string         // optional text message sent after the handshake
+	Expect *regexp.Regexp // optional: wait for a message matching this (any message when only Send is set)
 }
 
 // CheckJob is a single scheduled check request.
 type CheckJob struct {
 	Target      Target
 	ScheduledAt time.Time
-	Attempt     int // for retries later (MVP uses 1)
+	Attempt     int // first attempt number (scheduler uses 1); workers retry from here
 }
 
+// DefaultProbe names checks run by the central instance itself. Remote agents
+// report under their own probe name (see the agent package).
+const DefaultProbe = "primary"
+
 // CheckResult is the outcome of executing a CheckJob.
 type CheckResult struct {
 	TargetName string
 	URL        string
+	Probe      string // location that ran the check; empty means DefaultProbe
 
 	At      time.Time
 	Latency time.Duration
+	Timing  *Timing // per-phase breakdown for HTTP checks; nil for other types
 
 	Up         bool
-	StatusCode int // 0 if no response
+	Degraded   bool // Up but slower than Target.DegradedLatency
+	StatusCode int  // 0 if no response
 	Error      string
 	Validation string // e.g. "keyword missing", "unexpected status"
 
-	Attempt int
+	TLS *TLSInfo // nil when no TLS handshake happened
+
+	FinalURL  string        // URL of the response that was evaluated (after redirects)
+	Redirects []RedirectHop // redirect chain in order; empty when there were none
+
+	Steps []StepResult // per-step outcome for "steps" targets, up to the failing step
+
+	WebSocket *WebSocketInfo // handshake/message split for "websocket" targets
+
+	Content *ContentSnapshot // normalised body when the target watches content and the status passed
+
+	Alert AlertPolicy // copied from the Target by the worker; read by the Aggregator
+
+	Maintenance *maintenance.Active // set by the worker when a maintenance window covers the check
+
+	Attempt  int             // attempt that produced this outcome (1 = no retry needed)
+	Attempts []AttemptRecord // every attempt in order, including the final one; empty without retries
+}
+
+// AttemptRecord summarises one attempt of a retried check.
+type AttemptRecord struct {
+	Attempt    int
+	At         time.Time
+	Latency    time.Duration
+	Up         bool
+	StatusCode int
+	Reason     string // error or validation message of a failed attempt
+}
+
+// WebSocketInfo splits a websocket check's latency.
+type WebSocketInfo struct {
+	Handshake    time.Duration // dial + TLS + upgrade
+	FirstMessage time.Duration // after the handshake, until the expected message (0 when none configured)
+}
+
+// Check statuses, as stored in check_results.status and exposed in /status.
+const (
+	StatusUp       = "UP"
+	StatusDegraded = "DEGRADED"
+	StatusDown     = "DOWN"
+	StatusTimeout  = "TIMEOUT"
+
+	StatusMaintenance = "MAINTENANCE" // inside a planned maintenance window
+)
+
+// Status collapses Up/Degraded into the tri-state used by State and events.
+func (r CheckResult) Status() string {
+	switch {
+	case !r.Up:
+		return StatusDown
+	case r.Degraded:
+		return StatusDegraded
+	default:
+		return StatusUp
+	}
+}
+
+// RedirectHop is one redirect response seen while following a chain.
+type RedirectHop struct {
+	StatusCode int
+	URL        string // URL that answered with the redirect
+	Location   string // Location header it pointed to
+}
+
+// TLSInfo describes the certificate presented during a check.
+type TLSInfo struct {
+	NotAfter   time.Time
+	Issuer     string
+	Subject    string
+	SANs       []string
+	ChainValid bool
+	ChainError string // set when ChainValid is false
+	Expiring   bool   // NotAfter falls within the target's warning window
 }
 
-// State is the latest (rolling) view per target.
+// DaysToExpiry returns whole days until NotAfter (negative once expired).
+func (i *TLSInfo) DaysToExpiry(now time.Time) int {
+	return int(math.Floor(i.NotAfter.Sub(now).Hours() / 24))
+}
+
+// State is the latest (rolling) view per target and probe.
 type State struct {
-	Name string
-	URL  string
+	Name  string
+	URL   string
+	Probe string
 
+	// Confirmed state: only changes once down_after/up_after consecutive results agree.
 	LastUp         bool
+	LastStatus     string // UP, DEGRADED or DOWN
 	LastChecked    time.Time
 	LastLatency    time.Duration
 	LastStatusCode int
@@ -55,21 +253,94 @@ type State struct {
 	ConsecutiveSuccess int
 	ConsecutiveFail    int
 
+	// Unconfirmed flip in progress: PendingStatus (UP or DOWN) has been seen
+	// PendingCount times of the PendingNeeded required. Empty when none.
+	PendingStatus string
+	PendingCount  int
+	PendingNeeded int
+
+	// Confirmed UP/DOWN transitions within the target's flap window (oldest first).
+	RecentTransitions []time.Time
+	// Flapping is set while transitions exceed the flap threshold; individual
+	// DOWN/UP notifications are suppressed until it clears.
+	Flapping     bool
+	FlappingFrom time.Time
+
+	// Active maintenance window as of the last check (nil outside maintenance).
+	Maintenance *maintenance.Active
+
+	// Parents this target depends on (from its AlertPolicy) and, while this
+	// target is DOWN because of one of them, that parent's name.
+	DependsOn []string
+	CausedBy  string
+
 	TotalChecks int
 	TotalFails  int
 
+	LastFinalURL  string
+	LastRedirects []RedirectHop
+	LastSteps     []StepResult
+	LastTiming    *Timing
+	LastWebSocket *WebSocketInfo
+	LastAttempts  []AttemptRecord
+
+	// Last watched content; compared against each new check (see ContentWatch).
+	LastContent *ContentSnapshot
+
+	// Last certificate seen; kept across checks that fail before the handshake.
+	LastTLS *TLSInfo
+	// NotAfter of the certificate we already warned about, so we warn once per
+	// cert; kept in cert_warnings across restarts.
+	CertWarnedFor time.Time
+
 	// Optional: keep last N results for history (MVP can skip filling this)
 	History []CheckResult
 }
 
-// Event is emitted on transitions (UP->DOWN or DOWN->UP).
+// Event kinds.
+const (
+	EventTransition        = "TRANSITION"         // UP->DOWN or DOWN->UP
+	EventCertExpiring      = "CERT_EXPIRING"      // certificate within its warning window
+	EventDegraded          = "DEGRADED"           // UP->DEGRADED (slow but working)
+	EventDegradedRecovered = "DEGRADED_RECOVERED" // DEGRADED->UP or DEGRADED->DOWN
+	EventContentChanged    = "CONTENT_CHANGED"    // watched content hash changed
+	EventFlappingStarted   = "FLAPPING_STARTED"   // transition rate crossed the flap threshold
+	EventFlappingStopped   = "FLAPPING_STOPPED"   // a whole flap window passed without transitions
+)
+
+// Event is emitted on transitions (UP->DOWN or DOWN->UP) and on warnings
+// that don't change UP/DOWN state (see Kind).
 type Event struct {
+	Kind       string
 	TargetName string
 	URL        string
-	From bool
-	To   bool
-	At     time.Time
-	Reason string // error/validation/status explanation
-	StatusCode int 
-}
+	Probe      string   // probe whose state changed, or QuorumProbe for quorum decisions
+	Probes     []string // quorum decisions: probes that saw the target DOWN
+	From       bool
+	To         bool
+	At         time.Time
+	Reason     string // error/validation/status explanation
+	StatusCode int
+
+	FromStatus string // UP, DEGRADED or DOWN
+	ToStatus   string
+	Latency    time.Duration
+
+	TLS *TLSInfo // set for EventCertExpiring
 
+	FinalURL  string
+	Redirects []RedirectHop
+
+	Diff string // set for EventContentChanged: short "- old / + new" line diff
+
+	// Flapping marks transitions that happened while the target was flapping
+	// (recorded, not notified). For EventFlappingStarted/Stopped, FlappingFrom
+	// is when flapping began and Transitions the count within the window.
+	Flapping     bool
+	FlappingFrom time.Time
+	Transitions  int
+
+	// CausedBy names the parent target whose outage explains this transition.
+	// Such events are recorded on the incident but not notified.
+	CausedBy string
+}
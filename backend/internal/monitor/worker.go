@@ -2,21 +2,29 @@ package monitor
 
 import (
 	"context"
-	"net/http"
 	"sync"
+	"time"
+
+	"cy-platforms-status-monitor/internal/maintenance"
 )
 
 // StartWorkers starts a fixed worker pool that consumes jobs from jobsCh,
 // executes checks, and publishes results into resultsCh.
 //
-// - client should be a single reusable *http.Client shared by all workers.
+// - checkers resolves each job's Target.Type to the Checker that runs it.
+// - failed checks are retried per Target.Retries; only the final outcome is published,
+// carrying every attempt in Attempts. Retries stay on the same worker, which
+// sleeps through each RetryDelay: a failing target holds a worker for up to
+// (Retries+1)×Timeout + Retries×RetryDelay, so when many targets fail at once
+// other checks queue up and run late (see SchedulerStats). Size workerCount
+// with that in mind.
 // - resultsCh should be buffered to reduce stalling under load.
 // - caller controls shutdown via ctx cancellation.
 // - wg is optional but recommended so main() can wait for clean exit.
 func StartWorkers(
 	ctx context.Context,
 	workerCount int,
-	client *http.Client,
+	checkers *Registry,
 	jobsCh <-chan CheckJob,
 	resultsCh chan<- CheckResult,
 	wg *sync.WaitGroup,
@@ -40,16 +48,22 @@ func StartWorkers(
 						return
 					}
 
-					// Per-job timeout context
-					jobCtx, cancel := context.WithTimeout(ctx, job.Target.Timeout)
-					result := CheckOnce(jobCtx, client, job.Target)
-					cancel()
+					// No point retrying a target that is down for planned maintenance.
+					window, inMaintenance := maintenance.ActiveFor(job.Target.Name, job.Target.Tags, time.Now())
+					if inMaintenance {
+						job.Target.Retries = 0
+					}
+
+					result := runWithRetries(ctx, checkers, job)
 
 					// Fill fields that belong to the job, not the raw check
-					result.Attempt = job.Attempt
 					// Note: TargetName/URL are already set by CheckOnce, but safe either way:
 					result.TargetName = job.Target.Name
 					result.URL = job.Target.URL
+					result.Alert = job.Target.Alert
+					if inMaintenance {
+						result.Maintenance = &window
+					}
 
 					// Publish result (stop if shutting down)
 					select {
@@ -64,3 +78,53 @@ func StartWorkers(
 		}(i + 1)
 	}
 }
+
+// runWithRetries runs job's check, re-attempting failures up to Target.Retries
+// times. Each attempt gets its own Timeout; shutdown stops retrying early.
+func runWithRetries(ctx context.Context, checkers *Registry, job CheckJob) CheckResult {
+	first := job.Attempt
+	if first <= 0 {
+		first = 1
+	}
+	last := first + job.Target.Retries
+
+	var attempts []AttemptRecord
+	for attempt := first; ; attempt++ {
+		// Per-attempt timeout context
+		jobCtx, cancel := context.WithTimeout(ctx, job.Target.Timeout)
+		result := checkers.Check(jobCtx, job.Target)
+		cancel()
+
+		result.Attempt = attempt
+		if job.Target.Retries > 0 {
+			attempts = append(attempts, AttemptRecord{
+				Attempt:    attempt,
+				At:         result.At,
+				Latency:    result.Latency,
+				Up:         result.Up,
+				StatusCode: result.StatusCode,
+				Reason:     failureReason(result),
+			})
+			result.Attempts = attempts
+		}
+
+		if result.Up || attempt >= last || !sleepCtx(ctx, job.Target.RetryDelay) {
+			return result
+		}
+	}
+}
+
+// sleepCtx waits for d, returning false if ctx is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,130 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketChecker is the Checker for "websocket" targets (url: ws:// or wss://).
+// It performs the upgrade handshake and, when configured, sends a message and
+// waits for a reply matching a pattern. Latency covers the whole exchange;
+// the handshake and first-message split is recorded in CheckResult.WebSocket.
+type WebSocketChecker struct{}
+
+// NewWebSocketChecker returns a WebSocket checker.
+func NewWebSocketChecker() *WebSocketChecker {
+	return &WebSocketChecker{}
+}
+
+func (c *WebSocketChecker) Check(ctx context.Context, t Target) CheckResult {
+	start := time.Now()
+
+	res := CheckResult{
+		TargetName: t.Name,
+		URL:        t.URL,
+		At:         time.Now(),
+		Attempt:    1,
+	}
+
+	header := http.Header{}
+	for k, v := range t.Headers {
+		header.Set(k, v)
+	}
+	switch {
+	case t.BasicAuth != nil:
+		creds := t.BasicAuth.Username + ":" + t.BasicAuth.Password
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(creds)))
+	case t.BearerToken != "":
+		header.Set("Authorization", "Bearer "+t.BearerToken)
+	}
+
+	// Capture the handshake so certificate details flow through like https targets.
+	var tlsState *tls.ConnectionState
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		TLSHandshakeDone: func(cs tls.ConnectionState, err error) {
+			if err == nil {
+				tlsState = &cs
+			}
+		},
+	})
+
+	dialer := websocket.Dialer{HandshakeTimeout: t.Timeout}
+	conn, resp, err := dialer.DialContext(ctx, t.URL, header)
+	handshake := time.Since(start)
+	res.Latency = handshake
+	res.WebSocket = &WebSocketInfo{Handshake: handshake}
+	if resp != nil {
+		res.StatusCode = resp.StatusCode
+	}
+	if u, perr := url.Parse(t.URL); perr == nil {
+		res.TLS = inspectTLS(tlsState, u.Hostname(), t.TLS.ExpiryWarningDays, time.Now())
+	}
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+			res.Validation = fmt.Sprintf("websocket: upgrade refused with HTTP %d", resp.StatusCode)
+			return res
+		}
+		res.Error = classifyHTTPError(err)
+		return res
+	}
+	defer conn.Close()
+
+	ws := t.WebSocket
+	if ws.Send != "" || ws.Expect != nil {
+		if msg := exchangeWebSocket(ctx, conn, ws); msg != "" {
+			res.Validation = msg
+			res.Latency = time.Since(start)
+			return res
+		}
+		res.WebSocket.FirstMessage = time.Since(start) - handshake
+	}
+	res.Latency = time.Since(start)
+
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+
+	res.Up = true
+	return res
+}
+
+// exchangeWebSocket sends ws.Send (if set) and reads messages until one matches
+// ws.Expect (any message when Expect is nil) or ctx expires.
+// Returns "" on success, otherwise the reason.
+func exchangeWebSocket(ctx context.Context, conn *websocket.Conn, ws WebSocketOptions) string {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+		_ = conn.SetWriteDeadline(deadline)
+	}
+
+	if ws.Send != "" {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(ws.Send)); err != nil {
+			return fmt.Sprintf("websocket: send: %v", err)
+		}
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			var netErr interface{ Timeout() bool }
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				if ws.Expect != nil {
+					return fmt.Sprintf("websocket: no message matching %q before timeout", ws.Expect.String())
+				}
+				return "websocket: no message before timeout"
+			}
+			return fmt.Sprintf("websocket: read: %v", err)
+		}
+		if ws.Expect == nil || ws.Expect.Match(data) {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// echoServer upgrades requests carrying the "secret" bearer token, sends a
+// "hello" message and echoes every message back upper-cased.
+func echoServer(t *testing.T) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if r.URL.Path == "/silent" {
+			conn.ReadMessage()
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(strings.ToUpper(string(msg))))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestWebSocketChecker(t *testing.T) {
+	base := echoServer(t)
+
+	tests := []struct {
+		name      string
+		url       string
+		token     string
+		opts      WebSocketOptions
+		wantUp    bool
+		wantValid string // substring of Validation
+	}{
+		{name: "handshake only", url: base, token: "secret", wantUp: true},
+		{name: "any message", url: base, token: "secret", opts: WebSocketOptions{Send: "ping"}, wantUp: true},
+		{name: "reply matches", url: base, token: "secret", opts: WebSocketOptions{Send: "ping", Expect: regexp.MustCompile(`^PING$`)}, wantUp: true},
+		{name: "no matching reply", url: base, token: "secret", opts: WebSocketOptions{Send: "ping", Expect: regexp.MustCompile(`^pong$`)},
+			wantValid: `no message matching "^pong$" before timeout`},
+		{name: "no message", url: base + "/silent", token: "secret", opts: WebSocketOptions{Expect: regexp.MustCompile(`.`)},
+			wantValid: "before timeout"},
+		{name: "upgrade refused", url: base, wantValid: "upgrade refused with HTTP 401"},
+	}
+
+	c := NewWebSocketChecker()
+	for _, tt := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		res := c.Check(ctx, Target{Name: "t", URL: tt.url, BearerToken: tt.token, WebSocket: tt.opts})
+		cancel()
+
+		if res.Up != tt.wantUp {
+			t.Errorf("%s: up = %v, want %v (error %q, validation %q)", tt.name, res.Up, tt.wantUp, res.Error, res.Validation)
+		}
+		if !strings.Contains(res.Validation, tt.wantValid) || (tt.wantValid == "") != (res.Validation == "") {
+			t.Errorf("%s: validation %q, want %q", tt.name, res.Validation, tt.wantValid)
+		}
+		if res.WebSocket == nil {
+			t.Errorf("%s: want handshake timing", tt.name)
+		} else if tt.wantUp && tt.opts.Send != "" && res.WebSocket.FirstMessage <= 0 {
+			t.Errorf("%s: want first message timing", tt.name)
+		}
+	}
+}
+
+func TestWebSocketCheckerRefused(t *testing.T) {
+	res := NewWebSocketChecker().Check(context.Background(), Target{Name: "t", URL: "ws://" + closedAddr(t)})
+	if res.Up || !strings.Contains(res.Error, "connection refused") {
+		t.Errorf("up %v error %q, want connection refused", res.Up, res.Error)
+	}
+}
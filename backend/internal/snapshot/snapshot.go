@@ -4,24 +4,115 @@ import "sync/atomic"
 
 // Snapshot is the read-only view used by the API.
 type Snapshot struct {
-	All    []StateDTO
-	ByName map[string]StateDTO
+	All    []StateDTO          // one entry per (target, probe)
+	ByName map[string]StateDTO // primary probe only
 }
 
-// StateDTO is what the API exposes per target.
+// StateDTO is what the API exposes per target and probe.
 type StateDTO struct {
 	Name        string `json:"name"`
 	URL         string `json:"url"`
+	Probe       string `json:"probe"` // "primary" or the reporting agent's probe name
 	Up          bool   `json:"up"`
+	Status      string `json:"status"` // UP, DEGRADED, DOWN or MAINTENANCE
 	LastChecked string `json:"last_checked"`
 	LatencyMs   int64  `json:"latency_ms"`
-	StatusCode int    `json:"status_code"`
-	LastError  string `json:"last_error"`
+	StatusCode  int    `json:"status_code"`
+	LastError   string `json:"last_error"`
 
 	ConsecutiveSuccess int `json:"consecutive_success"`
 	ConsecutiveFail    int `json:"consecutive_fail"`
 	TotalChecks        int `json:"total_checks"`
 	TotalFails         int `json:"total_fails"`
+
+	// Parent target whose outage explains this target being DOWN
+	CausedBy string `json:"caused_by,omitempty"`
+
+	// Planned maintenance window covering the last check
+	Maintenance *MaintenanceDTO `json:"maintenance,omitempty"`
+
+	// Oscillating between UP and DOWN faster than the target's flap threshold
+	Flapping      bool   `json:"flapping"`
+	FlappingSince string `json:"flapping_since,omitempty"`
+
+	// Unconfirmed UP/DOWN flip waiting for up_after/down_after results
+	Pending *PendingDTO `json:"pending,omitempty"`
+
+	FinalURL  string           `json:"final_url,omitempty"`
+	Redirects []RedirectHopDTO `json:"redirects,omitempty"`
+
+	Steps []StepDTO `json:"steps,omitempty"`
+
+	// Every attempt of the last check when the target retries failures
+	Attempts []AttemptDTO `json:"attempts,omitempty"`
+
+	// HTTP phase breakdown of the last check (http targets only)
+	Timing *TimingDTO `json:"timing,omitempty"`
+
+	// Handshake/first-message split of the last check (websocket targets only)
+	WebSocket *WebSocketDTO `json:"websocket,omitempty"`
+
+	// TLS certificate (only for targets that completed a handshake)
+	CertNotAfter     string   `json:"cert_not_after,omitempty"`
+	CertDaysToExpiry *int     `json:"cert_days_to_expiry,omitempty"`
+	CertIssuer       string   `json:"cert_issuer,omitempty"`
+	CertSANs         []string `json:"cert_sans,omitempty"`
+	CertChainValid   *bool    `json:"cert_chain_valid,omitempty"`
+}
+
+// RedirectHopDTO is one hop of the last check's redirect chain.
+type RedirectHopDTO struct {
+	StatusCode int    `json:"status_code"`
+	URL        string `json:"url"`
+	Location   string `json:"location"`
+}
+
+// StepDTO is one step of a multi-step ("steps") target's last check.
+type StepDTO struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Up         bool   `json:"up"`
+	StatusCode int    `json:"status_code"`
+	LatencyMs  int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// MaintenanceDTO describes the maintenance window a target is in.
+type MaintenanceDTO struct {
+	Reason string `json:"reason,omitempty"`
+	Until  string `json:"until"`
+}
+
+// PendingDTO is a status change seen but not yet confirmed.
+type PendingDTO struct {
+	Status string `json:"status"` // UP or DOWN
+	Count  int    `json:"count"`  // consecutive results so far
+	Needed int    `json:"needed"` // results required to confirm
+}
+
+// AttemptDTO is one attempt of a retried check.
+type AttemptDTO struct {
+	Attempt    int    `json:"attempt"`
+	At         string `json:"at"`
+	LatencyMs  int64  `json:"latency_ms"`
+	Up         bool   `json:"up"`
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TimingDTO is the per-phase latency breakdown of an HTTP check.
+type TimingDTO struct {
+	DNSMs      int64 `json:"dns_ms"`
+	ConnectMs  int64 `json:"connect_ms"`
+	TLSMs      int64 `json:"tls_ms"`
+	TTFBMs     int64 `json:"ttfb_ms"`
+	TransferMs int64 `json:"transfer_ms"`
+}
+
+// WebSocketDTO splits a websocket check's latency.
+type WebSocketDTO struct {
+	HandshakeMs    int64 `json:"handshake_ms"`
+	FirstMessageMs int64 `json:"first_message_ms"`
 }
 
 var current atomic.Value // stores Snapshot
@@ -2,12 +2,13 @@ package main
 
 import (
 	"context"
+	"cy-platforms-status-monitor/internal/agent"
 	"cy-platforms-status-monitor/internal/config"
 	"cy-platforms-status-monitor/internal/handlers"
+	"cy-platforms-status-monitor/internal/maintenance"
 	"cy-platforms-status-monitor/internal/monitor"
 	"cy-platforms-status-monitor/internal/snapshot"
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -32,6 +33,12 @@ func main() {
 
 	godotenv.Load(".env")
 
+	// Agent mode: check from this location and report to the central instance.
+	if os.Getenv("RUN_MODE") == "agent" {
+		runAgent()
+		return
+	}
+
 	// Database: require DATABASE_URL and establish a pooled connection.
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
@@ -98,6 +105,8 @@ func main() {
 		IdleConnTimeout: 90 * time.Second,
 	})
 
+	checkers := monitor.NewDefaultRegistry(client)
+
 	var workerWg sync.WaitGroup
 
 	jobsCh := make(chan monitor.CheckJob, 200)
@@ -105,9 +114,14 @@ func main() {
 	eventsCh := make(chan monitor.Event, 50)
 
 	targetsToMonitor := toMonitorTargets(cfg.Targets)
+	monitor.PublishTargets(targetsToMonitor)
 
-	monitor.StartWorkers(ctx, cfg.Monitoring.Workers, client, jobsCh, resultsCh, &workerWg)
-	monitor.StartSchedulers(ctx, targetsToMonitor, jobsCh)
+	// Load maintenance windows before the first checks run.
+	maintenance.StartRefresher(ctx, dbpool, time.Minute)
+
+	monitor.StartWorkers(ctx, cfg.Monitoring.Workers, checkers, jobsCh, resultsCh, &workerWg)
+	sched := monitor.StartScheduler(ctx, targetsToMonitor, jobsCh)
+	pollConfig(ctx, cfg, sched)
 
 	go monitor.Aggregator(ctx, resultsCh, eventsCh, dbpool)
 
@@ -128,9 +142,42 @@ func main() {
 		}
 	})
 
+	r.Get("/scheduler", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		st := sched.Status()
+
+		if err := json.NewEncoder(w).Encode(map[string]any{
+			"targets":     st.Targets,
+			"dispatched":  st.Dispatched,
+			"delayed":     st.Delayed,
+			"last_lag_ms": st.LastLag.Milliseconds(),
+			"max_lag_ms":  st.MaxLag.Milliseconds(),
+		}); err != nil {
+			http.Error(w, "failed to encode scheduler stats", http.StatusInternalServerError)
+			return
+		}
+	})
+
 	h := handlers.New(dbpool)
 	r.Get("/uptime", h.GetUptime)
 	r.Get("/uptime/all", h.GetUptimeAll)
+	r.Get("/latency", h.GetLatency)
+
+	// Maintenance windows: anyone can read, writes need ADMIN_API_TOKEN.
+	r.Get("/maintenance", h.ListMaintenance)
+	if adminToken := os.Getenv("ADMIN_API_TOKEN"); adminToken != "" {
+		r.With(handlers.RequireToken(adminToken)).Post("/maintenance", h.CreateMaintenance)
+		r.With(handlers.RequireToken(adminToken)).Delete("/maintenance/{id}", h.DeleteMaintenance)
+	} else {
+		log.Println("ADMIN_API_TOKEN not set — maintenance windows are read-only via the API")
+	}
+
+	// Remote probe agents push their results here (see runAgent).
+	if ingestToken := os.Getenv("INGEST_TOKEN"); ingestToken != "" {
+		r.With(handlers.RequireToken(ingestToken)).Post(agent.IngestPath, handlers.Ingest(resultsCh))
+	} else {
+		log.Println("INGEST_TOKEN not set — remote probe agents can't report")
+	}
 	// Serve Vite build output from /app/web/dist
 	fs := http.FileServer(http.Dir("./web/dist"))
 
@@ -152,31 +199,263 @@ func main() {
 		http.ServeFile(w, r, "./web/dist/index.html")
 	})
 
-	fmt.Println(cfg)
+	// Don't print the whole config: targets may carry credentials.
+	log.Printf("loaded %d targets from %s", len(cfg.Targets), CONFIGS_PATH)
 	http.ListenAndServe(":8080", r)
 }
 
+// runAgent runs schedulers and workers only and forwards every result to the
+// central instance at CENTRAL_URL, recorded there as probe AGENT_PROBE. The
+// agent needs no database or Telegram: the central instance stores results
+// and raises incidents.
+func runAgent() {
+	probe := os.Getenv("AGENT_PROBE")
+	centralURL := os.Getenv("CENTRAL_URL")
+	token := os.Getenv("INGEST_TOKEN")
+	if probe == "" || centralURL == "" || token == "" {
+		log.Fatal("AGENT_PROBE, CENTRAL_URL and INGEST_TOKEN env vars are required when RUN_MODE=agent")
+	}
+	if probe == monitor.DefaultProbe {
+		log.Fatalf("AGENT_PROBE must not be %q: that name belongs to the central instance", monitor.DefaultProbe)
+	}
+
+	cfg, err := config.Load(CONFIGS_PATH)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	client := monitor.NewHTTPClient(monitor.HTTPClientConfig{
+		Timeout:         10 * time.Second,
+		UserAgent:       cfg.Monitoring.UserAgent,
+		MaxIdleConns:    100,
+		IdleConnTimeout: 90 * time.Second,
+	})
+
+	var workerWg sync.WaitGroup
+
+	jobsCh := make(chan monitor.CheckJob, 200)
+	resultsCh := make(chan monitor.CheckResult, 200)
+
+	monitor.StartWorkers(ctx, cfg.Monitoring.Workers, monitor.NewDefaultRegistry(client), jobsCh, resultsCh, &workerWg)
+	sched := monitor.StartScheduler(ctx, toMonitorTargets(cfg.Targets), jobsCh)
+	pollConfig(ctx, cfg, sched)
+
+	r := chi.NewRouter()
+	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"ok":true}`))
+	})
+	go http.ListenAndServe(":8080", r)
+
+	log.Printf("agent %q: loaded %d targets from %s, reporting to %s", probe, len(cfg.Targets), CONFIGS_PATH, centralURL)
+	agent.Forward(ctx, resultsCh, agent.Config{
+		CentralURL: centralURL,
+		Token:      token,
+		Probe:      probe,
+	})
+}
+
+// pollConfig applies edits to CONFIGS_PATH (picked up by polling every 5s, or
+// on SIGHUP) without a restart: only added, changed and removed targets are
+// rescheduled, and the Aggregator keeps the state of the rest. An invalid file
+// is rejected and the running config stays in place.
+func pollConfig(ctx context.Context, running *config.Config, sched *monitor.Scheduler) {
+	config.Poll(ctx, CONFIGS_PATH, 5*time.Second, func() {
+		next, err := config.Load(CONFIGS_PATH)
+		if err != nil {
+			log.Printf("config reload rejected, keeping the running config: %v", err)
+			return
+		}
+		if next.Monitoring != running.Monitoring {
+			log.Println("config reload: monitoring settings (workers, user_agent, ...) only apply after a restart")
+		}
+
+		targets := toMonitorTargets(next.Targets)
+		monitor.PublishTargets(targets)
+		sched.Update(ctx, targets)
+		running = next
+		log.Printf("config reloaded: %d targets from %s", len(next.Targets), CONFIGS_PATH)
+	})
+}
+
 func toMonitorTargets(ct []config.Target) []monitor.Target {
 	out := make([]monitor.Target, 0, len(ct))
 	for _, t := range ct {
-		enabled := true
-		if t.Enabled != nil {
-			enabled = *t.Enabled
+		mt := toMonitorTarget(t)
+		mt.ConfigHash = t.Fingerprint()
+		out = append(out, mt)
+	}
+
+	return out
+}
+
+func toMonitorTarget(t config.Target) monitor.Target {
+	enabled := true
+	if t.Enabled != nil {
+		enabled = *t.Enabled
+	}
+
+	followRedirects := true
+	if t.FollowRedirects != nil {
+		followRedirects = *t.FollowRedirects
+	}
+
+	return monitor.Target{
+		Name:           t.Name,
+		Type:           t.Type,
+		URL:            t.URL,
+		Method:         t.Method,
+		Interval:       t.IntervalDur,
+		Timeout:        t.TimeoutDur,
+		ExpectedStatus: toMonitorStatusRanges(t.ExpectedStatus),
+		Contains:       t.Contains,
+		MaxBodyBytes:   t.MaxBodyBytes,
+		JSONAssert:     toMonitorJSONAssertions(t.JSONAssert),
+		ExpectHeaders:  toMonitorHeaderAssertions(t.ExpectHeaders),
+		Matches:        t.MatchesRe,
+		NotContains:    t.NotContains,
+		NotMatches:     t.NotMatchesRe,
+		Headers:        t.Headers,
+		Body:           t.Body,
+		BasicAuth:      toMonitorBasicAuth(t.BasicAuth),
+		BearerToken:    t.BearerToken,
+
+		FollowRedirects:  followRedirects,
+		MaxRedirects:     t.MaxRedirects,
+		ExpectedFinalURL: t.ExpectedFinalURL,
+
+		DegradedLatency: t.DegradedLatencyDur,
+
+		Retries:    t.Retries,
+		RetryDelay: t.RetryDelayDur,
+
+		Alert: monitor.AlertPolicy{
+			DownAfter:     t.DownAfter,
+			UpAfter:       t.UpAfter,
+			FlapThreshold: t.FlapThreshold,
+			FlapWindow:    t.FlapWindowDur,
+			DependsOn:     t.DependsOn,
+			Quorum:        t.Quorum,
+			QuorumWindow:  quorumWindow(t),
+		},
+
+		Enabled: enabled,
+		Tags:    t.Tags,
+		TCP: monitor.TCPOptions{
+			BannerPrefix: t.TCP.BannerPrefix,
+		},
+		DNS: monitor.DNSOptions{
+			RecordType: t.DNS.RecordType,
+			Resolver:   t.DNS.Resolver,
+			Expect:     t.DNS.Expect,
+			MinRecords: t.DNS.MinRecords,
+		},
+		TLS: monitor.TLSOptions{
+			ExpiryWarningDays: t.TLS.ExpiryWarningDays,
+		},
+		GRPC: monitor.GRPCOptions{
+			Service:            t.GRPC.Service,
+			TLS:                t.GRPC.TLS,
+			InsecureSkipVerify: t.GRPC.InsecureSkipVerify,
+		},
+		WebSocket: monitor.WebSocketOptions{
+			Send:   t.WebSocket.Send,
+			Expect: t.WebSocket.ExpectRe,
+		},
+		Steps: toMonitorSteps(t.Steps),
+
+		WatchContent: toMonitorContentWatch(t.WatchContent),
+	}
+}
+
+// quorumWindow is how recent a probe's last result must be to count towards a
+// quorum: one interval plus the longest a check can take with all its retries,
+// plus the delay before an agent forwards the result.
+func quorumWindow(t config.Target) time.Duration {
+	check := t.TimeoutDur + time.Duration(t.Retries)*(t.TimeoutDur+t.RetryDelayDur)
+	return t.IntervalDur + check + agent.DefaultFlushInterval
+}
+
+func toMonitorSteps(in []config.Step) []monitor.Step {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]monitor.Step, 0, len(in))
+	for _, s := range in {
+		step := monitor.Step{Request: toMonitorTarget(s.Target)}
+		for _, e := range s.Extract {
+			ex := monitor.Extraction{Var: e.Var}
+			switch {
+			case e.Header != "":
+				ex.From, ex.Key = monitor.ExtractHeader, e.Header
+			case e.Regex != "":
+				ex.From, ex.Pattern = monitor.ExtractRegex, e.RegexRe
+			default:
+				ex.From, ex.Key = monitor.ExtractJSON, e.JSON
+			}
+			step.Extract = append(step.Extract, ex)
 		}
+		out = append(out, step)
+	}
+	return out
+}
+
+func toMonitorJSONAssertions(in []config.JSONAssertion) []monitor.JSONAssertion {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]monitor.JSONAssertion, 0, len(in))
+	for _, a := range in {
+		out = append(out, monitor.JSONAssertion{
+			Path:    a.Path,
+			Op:      a.Op,
+			Value:   a.ValueText,
+			Pattern: a.ValueRe,
+		})
+	}
+	return out
+}
 
-		out = append(out, monitor.Target{
-			Name:           t.Name,
-			URL:            t.URL,
-			Method:         t.Method,
-			Interval:       t.IntervalDur,
-			Timeout:        t.TimeoutDur,
-			ExpectedStatus: t.ExpectedStatus,
-			Contains:       t.Contains,
-			MaxBodyBytes:   t.MaxBodyBytes,
-			Enabled:        enabled,
-			Tags:           t.Tags,
+func toMonitorHeaderAssertions(in []config.HeaderAssertion) []monitor.HeaderAssertion {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]monitor.HeaderAssertion, 0, len(in))
+	for _, a := range in {
+		out = append(out, monitor.HeaderAssertion{
+			Name:    a.Name,
+			Op:      a.Op,
+			Value:   a.Value,
+			Pattern: a.ValueRe,
 		})
 	}
+	return out
+}
+
+func toMonitorContentWatch(in *config.WatchContent) *monitor.ContentWatch {
+	if in == nil {
+		return nil
+	}
+	return &monitor.ContentWatch{Selector: in.SelectorSel, SelectorSource: in.Selector, Region: in.RegionRe}
+}
 
+func toMonitorBasicAuth(in *config.BasicAuth) *monitor.BasicAuth {
+	if in == nil {
+		return nil
+	}
+	return &monitor.BasicAuth{Username: in.Username, Password: in.Password}
+}
+
+func toMonitorStatusRanges(in config.ExpectedStatus) []monitor.StatusRange {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]monitor.StatusRange, 0, len(in))
+	for _, r := range in {
+		out = append(out, monitor.StatusRange{Min: r.Min, Max: r.Max})
+	}
 	return out
 }
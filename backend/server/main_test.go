@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"cy-platforms-status-monitor/internal/config"
+	"cy-platforms-status-monitor/internal/monitor"
+)
+
+func writeConfig(t *testing.T, yaml string) {
+	t.Helper()
+	if err := os.WriteFile(CONFIGS_PATH, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPollConfigReload(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("configs", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, "targets:\n  - name: old\n    url: https://example.com\n    interval: 20ms\n")
+
+	cfg, err := config.Load(CONFIGS_PATH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	targets := toMonitorTargets(cfg.Targets)
+	monitor.PublishTargets(targets)
+	jobsCh := make(chan monitor.CheckJob, 100)
+	sched := monitor.StartScheduler(ctx, targets, jobsCh)
+	pollConfig(ctx, cfg, sched)
+
+	reload := func(yaml string) {
+		t.Helper()
+		writeConfig(t, yaml)
+		if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	reload("targets:\n  - name: new\n    url: https://example.com\n    interval: 20ms\n")
+	if _, ok := monitor.LookupTarget("old"); ok {
+		t.Error("removed target still published")
+	}
+	if _, ok := monitor.LookupTarget("new"); !ok {
+		t.Error("added target not published")
+	}
+
+	// An invalid file keeps the running config.
+	reload("targets:\n  - name: new\n    url: ftp://example.com\n")
+	if _, ok := monitor.LookupTarget("new"); !ok {
+		t.Error("invalid config replaced the running one")
+	}
+
+	for len(jobsCh) > 0 {
+		<-jobsCh
+	}
+	for range 3 {
+		if job := <-jobsCh; job.Target.Name != "new" {
+			t.Errorf("scheduled %q after the reload, want only new", job.Target.Name)
+		}
+	}
+}
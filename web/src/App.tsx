@@ -4,7 +4,9 @@ import logo from "./assets/cyobserver.png";
 type StateDTO = {
   name: string;
   url: string;
+  probe?: string; // "primary" or a remote agent's probe name
   up: boolean;
+  status?: "UP" | "DEGRADED" | "DOWN" | "MAINTENANCE";
   last_checked: string;
   latency_ms: number;
   status_code: number;
@@ -13,6 +15,8 @@ type StateDTO = {
   consecutive_fail: number;
   total_checks: number;
   total_fails: number;
+  pending?: { status: "UP" | "DOWN"; count: number; needed: number };
+  maintenance?: { reason?: string; until: string };
 };
 
 type UptimeItem = {
@@ -55,12 +59,18 @@ function pctTone(pct: number) {
   return "bg-rose-50 text-rose-800 ring-rose-200";
 }
 
-function StatusBadge({ up }: { up: boolean }) {
+function StatusBadge({ up, status }: { up: boolean; status?: string }) {
+  const degraded = up && status === "DEGRADED";
+  const maintenance = status === "MAINTENANCE";
   return (
     <span
       className={classNames(
         "inline-flex items-center gap-2 rounded-full px-3 py-1 text-xs font-semibold ring-1",
-        up
+        maintenance
+          ? "bg-sky-50 text-sky-800 ring-sky-200"
+          : degraded
+          ? "bg-amber-50 text-amber-800 ring-amber-200"
+          : up
           ? "bg-emerald-50 text-emerald-800 ring-emerald-200"
           : "bg-rose-50 text-rose-800 ring-rose-200"
       )}
@@ -68,10 +78,16 @@ function StatusBadge({ up }: { up: boolean }) {
       <span
         className={classNames(
           "h-2 w-2 rounded-full",
-          up ? "bg-emerald-600" : "bg-rose-600"
+          maintenance
+            ? "bg-sky-500"
+            : degraded
+            ? "bg-amber-500"
+            : up
+            ? "bg-emerald-600"
+            : "bg-rose-600"
         )}
       />
-      {up ? "UP" : "DOWN"}
+      {maintenance ? "MAINTENANCE" : degraded ? "DEGRADED" : up ? "UP" : "DOWN"}
     </span>
   );
 }
@@ -165,7 +181,10 @@ export default function App() {
       .slice()
       .sort((a, b) => {
         if (a.up !== b.up) return a.up ? 1 : -1; // DOWN first
-        return (a.name || "").localeCompare(b.name || "");
+        return (
+          (a.name || "").localeCompare(b.name || "") ||
+          (a.probe || "").localeCompare(b.probe || "")
+        );
       })
       .filter((it) => (onlyDown ? !it.up : true))
       .filter((it) =>
@@ -335,12 +354,24 @@ export default function App() {
                   </tr>
                 ) : (
                   filtered.map((it) => (
-                    <tr key={it.name} className="hover:bg-slate-50/40">
+                    <tr key={`${it.name}@${it.probe ?? ""}`} className="hover:bg-slate-50/40">
                       <td className="px-5 py-4">
-                        <StatusBadge up={it.up} />
+                        <StatusBadge up={it.up} status={it.status} />
+                        {it.pending ? (
+                          <div className="mt-1 text-xs text-slate-500">
+                            pending {it.pending.status} ({it.pending.count}/{it.pending.needed})
+                          </div>
+                        ) : null}
                       </td>
                       <td className="px-5 py-4">
-                        <div className="font-semibold text-slate-900">{it.name}</div>
+                        <div className="font-semibold text-slate-900">
+                          {it.name}
+                          {it.probe && it.probe !== "primary" ? (
+                            <span className="ml-2 text-xs font-normal text-slate-500">
+                              via {it.probe}
+                            </span>
+                          ) : null}
+                        </div>
                         {it.url ? (
                           <a
                             className="mt-1 block text-xs text-sky-700 hover:underline"